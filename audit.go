@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"walletscrambler/internal/sysinfo"
+)
+
+// errEnvironmentRejected is returned when the user declines to continue on
+// a machine that did not pass the environment audit.
+var errEnvironmentRejected = errors.New("the environment audit was not accepted")
+
+// statusStyles colours each audit status.
+var statusStyles = map[sysinfo.Status]string{
+	sysinfo.Pass: "{green}",
+	sysinfo.Warn: "{yellow}",
+	sysinfo.Fail: "{red}",
+}
+
+// printAudit prints one line per audit finding.
+func printAudit(findings []sysinfo.Finding) {
+	for _, f := range findings {
+		printStyled(fmt.Sprintf("  %s[%s]{reset} %s: %s\n", statusStyles[f.Status], f.Status, f.Check, f.Detail))
+	}
+}
+
+// runAudit prints the environment audit, so a machine can be checked before
+// a ceremony. It fails when a check fails, or with -strict when any check is
+// not passed.
+func runAudit(args []string) int {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	strict := fs.Bool("strict", false, "also fail on warnings")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "audit: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+	findings := sysinfo.Audit()
+	printAudit(findings)
+	worst := sysinfo.Worst(findings)
+	if worst == sysinfo.Fail || *strict && worst != sysinfo.Pass {
+		return exitError
+	}
+	return exitOK
+}
+
+// auditOptions holds the audit flags of the subcommands that take secrets.
+type auditOptions struct {
+	strict bool // also refuse to run on warnings
+	ignore bool // run even when a check fails
+}
+
+// register adds the audit flags to fs.
+func (a *auditOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&a.strict, "strict", false, "refuse to run unless every environment audit check passes")
+	fs.BoolVar(&a.ignore, "ignore-audit", false, "run even when an environment audit check fails, such as on a machine with a network")
+}
+
+// auditCommand runs the environment audit for a scripted command, which
+// cannot ask whether to continue. Checks that did not pass are reported on
+// standard error. A failed check stops the command unless the audit is
+// explicitly ignored, and with strict any check that did not pass does.
+func auditCommand(name string, opts auditOptions, stderr io.Writer) error {
+	findings := sysinfo.Audit()
+	for _, f := range findings {
+		if f.Status != sysinfo.Pass {
+			fmt.Fprintf(stderr, "%s: audit: [%s] %s: %s\n", name, f.Status, f.Check, f.Detail)
+		}
+	}
+	switch worst := sysinfo.Worst(findings); {
+	case opts.strict && worst != sysinfo.Pass:
+		return errors.New("refusing to run: not every check passed (-strict)")
+	case worst == sysinfo.Fail && !opts.ignore:
+		return errors.New("refusing to run: this machine is not air-gapped; pass -ignore-audit to run anyway")
+	}
+	return nil
+}
+
+// confirmEnvironment shows the environment audit at the start of an
+// interactive session, before any secret is entered. Unless every check
+// passed, the user must explicitly choose to continue.
+func confirmEnvironment(p *prompter, findings []sysinfo.Finding) error {
+	printStyled("{bold}{cyan}Environment audit:\n")
+	printAudit(findings)
+	printStyled("\n")
+	switch sysinfo.Worst(findings) {
+	case sysinfo.Pass:
+		return nil
+	case sysinfo.Fail:
+		printStyled("{red}{bold}This machine is NOT air-gapped. Secrets entered on it may leak.\n\n")
+	default:
+		printStyled("{yellow}Review the warnings above before entering any secret.\n\n")
+	}
+	proceed, err := choice(p, "Do you want to continue on this machine anyway?", "Continue anyway", "Quit", "C", "Q")
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		return errEnvironmentRejected
+	}
+	printStyled("\n")
+	return nil
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"walletscrambler/internal/secmem"
+	"walletscrambler/scrambler"
+)
+
+// batchOptions holds the flags of the batch command.
+type batchOptions struct {
+	mode         scrambler.Mode
+	profile      scrambler.KDFProfile
+	input        string
+	salt         string
+	saltCount    int
+	saltSource   saltSourceOptions
+	checkWords   int
+	unscramble   bool
+	passwordFile string
+	format       string
+	progress     string
+	audit        auditOptions
+}
+
+// batchEntry is one labelled wallet of a batch file.
+type batchEntry struct {
+	line  int
+	label string
+	words []string
+	check []string
+}
+
+type batchResult struct {
+	Mode    string        `json:"mode"`
+	Profile string        `json:"profile"`
+	Salt    []string      `json:"salt,omitempty"`
+	Wallets []batchWallet `json:"wallets"`
+}
+
+type batchWallet struct {
+	Label string   `json:"label"`
+	Words []string `json:"words"`
+	Check []string `json:"check,omitempty"`
+}
+
+// runBatch scrambles or unscrambles every wallet of a batch file with one
+// key derivation, giving each wallet its own key through its label.
+func runBatch(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts batchOptions
+	var modeName, profileID string
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&modeName, "mode", scrambler.ModeWords.Name(), "how wallet words are interpreted: "+modeNames())
+	fs.StringVar(&profileID, "profile", scrambler.DefaultProfile, "KDF profile: "+profileIDs())
+	fs.StringVar(&opts.input, "input", "", "read the wallets from `file` (default: the rest of stdin)")
+	fs.StringVar(&opts.salt, "salt", "", "salt words separated by spaces or commas")
+	fs.IntVar(&opts.saltCount, "salt-count", 0, "number of salt words to generate when -salt is not given")
+	opts.saltSource.register(fs, "-salt-count")
+	fs.IntVar(&opts.checkWords, "check-words", 0, fmt.Sprintf("number of check words to emit per wallet (0 to %d)", scrambler.MaxCheckWords))
+	fs.BoolVar(&opts.unscramble, "unscramble", false, "unscramble the wallets instead of scrambling them")
+	fs.StringVar(&opts.passwordFile, "password-file", "", "read the password from the first line of `file`")
+	fs.StringVar(&opts.format, "format", "text", "output format: text or json")
+	fs.StringVar(&opts.progress, "progress", "auto", "key derivation progress on stderr: "+strings.Join(progressFormats, ", "))
+	opts.audit.register(fs)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), `Usage: wallet-scrambler batch [flags]
+
+Each line of the input is "label: word word ...", optionally followed by
+"| check words" when unscrambling. Empty lines and lines starting with # are
+ignored. Labels must be unique; they are mixed into the key so every wallet
+gets an independent key from a single key derivation.
+
+`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "batch: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+	mode, ok := scrambler.ModeByName(modeName)
+	if !ok {
+		fmt.Fprintf(stderr, "batch: unknown mode %q\n", modeName)
+		return exitUsage
+	}
+	opts.mode = mode
+	profile, ok := scrambler.LookupProfile(profileID)
+	if !ok {
+		fmt.Fprintf(stderr, "batch: unknown KDF profile %q\n", profileID)
+		return exitUsage
+	}
+	opts.profile = profile
+	if err := checkMemory(profile); err != nil {
+		fmt.Fprintf(stderr, "batch: %v\n", err)
+		return exitError
+	}
+	if opts.format != "text" && opts.format != "json" {
+		fmt.Fprintf(stderr, "batch: unknown format %q\n", opts.format)
+		return exitUsage
+	}
+	if !validProgressFormat(opts.progress) {
+		fmt.Fprintf(stderr, "batch: unknown progress format %q\n", opts.progress)
+		return exitUsage
+	}
+	if opts.unscramble && (opts.saltCount > 0 || opts.checkWords > 0) {
+		fmt.Fprintln(stderr, "batch: -salt-count and -check-words only apply when scrambling")
+		return exitUsage
+	}
+	if opts.salt != "" && opts.saltCount > 0 {
+		fmt.Fprintln(stderr, "batch: -salt and -salt-count are mutually exclusive")
+		return exitUsage
+	}
+	if opts.saltCount < 0 || opts.saltCount > scrambler.MaxSaltWords {
+		fmt.Fprintf(stderr, "batch: -salt-count must be between 0 and %d\n", scrambler.MaxSaltWords)
+		return exitUsage
+	}
+	if err := opts.saltSource.check("-salt-count", opts.saltCount); err != nil {
+		fmt.Fprintf(stderr, "batch: %v\n", err)
+		return exitUsage
+	}
+	if opts.checkWords < 0 || opts.checkWords > scrambler.MaxCheckWords {
+		fmt.Fprintf(stderr, "batch: -check-words must be between 0 and %d\n", scrambler.MaxCheckWords)
+		return exitUsage
+	}
+
+	if err := auditCommand("batch", opts.audit, stderr); err != nil {
+		fmt.Fprintf(stderr, "batch: %v\n", err)
+		return exitError
+	}
+	if err := batch(opts, stdin, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "batch: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func batch(opts batchOptions, in io.Reader, stdout, stderr io.Writer) error {
+	s := scrambler.New(scrambler.WithMode(opts.mode), scrambler.WithProfile(opts.profile),
+		scrambler.WithProgress(progressReporter(stderr, opts.progress)))
+	stdin := newPrompter(in, stderr)
+	defer stdin.wipe()
+
+	password, err := readPassword(opts.passwordFile, stdin, "Password: ")
+	if err != nil {
+		return err
+	}
+	if !opts.unscramble && isWeakPassword(password) {
+		fmt.Fprintln(stderr, "warning: the password is weak")
+	}
+	saltWords := splitWords(opts.salt, s.Wordlist())
+	if opts.saltCount > 0 {
+		if saltWords, err = opts.saltSource.generate(stdin, s.Wordlist(), opts.saltCount); err != nil {
+			return err
+		}
+	}
+
+	wallets := in
+	if opts.input != "" {
+		f, err := os.Open(opts.input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		wallets = f
+	}
+	data := secmem.New(maxInputLen)
+	_, err = data.ReadFrom(wallets)
+	entries, parseErr := parseBatch(data.Bytes(), s.Wordlist())
+	data.Destroy()
+	if err != nil {
+		return fmt.Errorf("reading wallets: %w", err)
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+	defer func() {
+		for _, e := range entries {
+			clearWords(e.words)
+		}
+	}()
+	for _, e := range entries {
+		if err := s.ValidateWords(e.words); err != nil {
+			return fmt.Errorf("line %d (%s): %w", e.line, e.label, err)
+		}
+		for i, word := range e.check {
+			if !s.Wordlist().Contains(word) {
+				return fmt.Errorf("line %d (%s): check word %d (%q) is not in the wordlist", e.line, e.label, i+1, word)
+			}
+		}
+	}
+
+	fmt.Fprintf(stderr, "Deriving the key once for %d wallets...\n", len(entries))
+	key, err := s.DeriveKey(password, saltWords)
+	if err != nil {
+		return err
+	}
+	defer key.Wipe()
+
+	result := batchResult{Mode: opts.mode.Name(), Profile: opts.profile.ID}
+	if !opts.unscramble {
+		result.Salt = saltWords
+	}
+	defer func() {
+		for _, w := range result.Wallets {
+			clearWords(w.Words)
+		}
+	}()
+	for _, e := range entries {
+		wallet, err := batchWalletResult(key, e, opts)
+		if err != nil {
+			return fmt.Errorf("line %d (%s): %w", e.line, e.label, err)
+		}
+		result.Wallets = append(result.Wallets, wallet)
+	}
+	return writeBatchResult(stdout, opts.format, result)
+}
+
+// batchWalletResult scrambles or unscrambles one wallet with its own key.
+func batchWalletResult(key *scrambler.Key, e batchEntry, opts batchOptions) (batchWallet, error) {
+	wallet := batchWallet{Label: e.label}
+	walletKey, err := key.ForWallet(e.label)
+	if err != nil {
+		return wallet, err
+	}
+	defer walletKey.Wipe()
+	if opts.unscramble {
+		if len(e.check) > 0 {
+			if err := walletKey.VerifyCheckWords(e.words, e.check); err != nil {
+				return wallet, err
+			}
+		}
+		wallet.Words, err = walletKey.Unscramble(e.words)
+		return wallet, err
+	}
+	if wallet.Words, err = walletKey.Scramble(e.words); err != nil {
+		return wallet, err
+	}
+	if opts.checkWords > 0 {
+		wallet.Check, err = walletKey.CheckWords(wallet.Words, opts.checkWords)
+	}
+	return wallet, err
+}
+
+// parseBatch parses "label: words [| check words]" lines. The words are
+// interned so data can be wiped afterwards.
+func parseBatch(data []byte, list scrambler.Wordlist) ([]batchEntry, error) {
+	var entries []batchEntry
+	labels := map[string]int{}
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		colon := bytes.IndexByte(line, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("line %d: expected \"label: words\"", i+1)
+		}
+		e := batchEntry{line: i + 1, label: string(bytes.TrimSpace(line[:colon]))}
+		if e.label == "" {
+			return nil, fmt.Errorf("line %d: empty label", e.line)
+		}
+		if first, ok := labels[e.label]; ok {
+			return nil, fmt.Errorf("line %d: label %q already used on line %d", e.line, e.label, first)
+		}
+		labels[e.label] = e.line
+		words := line[colon+1:]
+		if bar := bytes.IndexByte(words, '|'); bar >= 0 {
+			e.check = internWords(words[bar+1:], list)
+			words = words[:bar]
+		}
+		e.words = internWords(words, list)
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no wallets in the input")
+	}
+	return entries, nil
+}
+
+func writeBatchResult(w io.Writer, format string, result batchResult) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	if len(result.Salt) > 0 {
+		fprintBeautifully(w, "Salt:", result.Salt)
+	}
+	for _, wallet := range result.Wallets {
+		fprintBeautifully(w, "Wallet "+wallet.Label+":", wallet.Words)
+		if len(wallet.Check) > 0 {
+			fprintBeautifully(w, "Check Words ("+wallet.Label+"):", wallet.Check)
+		}
+	}
+	fmt.Fprintf(w, "\nKDF profile: %s\n", result.Profile)
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"walletscrambler/scrambler"
+)
+
+func TestParseBatch(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		input string
+		want  []batchEntry
+		err   string
+	}{
+		{
+			name:  "one wallet",
+			input: "savings: academic acid acne\n",
+			want:  []batchEntry{{line: 1, label: "savings", words: []string{"academic", "acid", "acne"}}},
+		},
+		{
+			name:  "comments and blank lines",
+			input: "# wallets of 2026\n\n  \nsavings: academic acid\n   # indented comment\r\nspending: acne, acquire\r\n\n",
+			want: []batchEntry{
+				{line: 4, label: "savings", words: []string{"academic", "acid"}},
+				{line: 6, label: "spending", words: []string{"acne", "acquire"}},
+			},
+		},
+		{
+			name:  "check words",
+			input: "savings: academic acid | public silver\nspending: acne acquire|zero\n",
+			want: []batchEntry{
+				{line: 1, label: "savings", words: []string{"academic", "acid"}, check: []string{"public", "silver"}},
+				{line: 2, label: "spending", words: []string{"acne", "acquire"}, check: []string{"zero"}},
+			},
+		},
+		{
+			name:  "label with spaces and words resolved",
+			input: "  cold storage 2 :Academic ACAD\n",
+			want:  []batchEntry{{line: 1, label: "cold storage 2", words: []string{"academic", "academic"}}},
+		},
+		{
+			name:  "unknown words are kept for validation",
+			input: "savings: acadmic acid\n",
+			want:  []batchEntry{{line: 1, label: "savings", words: []string{"acadmic", "acid"}}},
+		},
+		{
+			name:  "duplicate label",
+			input: "savings: academic\n# again\nsavings: acid\n",
+			err:   `line 3: label "savings" already used on line 1`,
+		},
+		{
+			name:  "duplicate label after trimming",
+			input: "savings: academic\n savings : acid\n",
+			err:   `line 2: label "savings" already used on line 1`,
+		},
+		{
+			name:  "missing colon",
+			input: "savings academic acid\n",
+			err:   `line 1: expected "label: words"`,
+		},
+		{
+			name:  "empty label",
+			input: "# first\n : academic acid\n",
+			err:   "line 2: empty label",
+		},
+		{
+			name:  "only comments",
+			input: "# nothing here\n\n",
+			err:   "no wallets in the input",
+		},
+		{
+			name:  "empty input",
+			input: "",
+			err:   "no wallets in the input",
+		},
+	} {
+		got, err := parseBatch([]byte(tt.input), scrambler.SLIP39)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: parseBatch error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: parseBatch: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseBatch = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"walletscrambler/scrambler"
+)
+
+// benchResult is the outcome of the bench command. Durations are in
+// seconds.
+type benchResult struct {
+	Profile       string  `json:"profile"`
+	SHA3Seconds   float64 `json:"sha3_seconds"`
+	Argon2Seconds float64 `json:"argon2_seconds"`
+	TotalSeconds  float64 `json:"total_seconds"`
+	Target        float64 `json:"target_seconds,omitempty"`
+	Proposed      string  `json:"proposed_profile,omitempty"`
+}
+
+// runBench times the key derivation stages of a profile on this machine and,
+// with -target, proposes a custom profile that takes about that long.
+func runBench(args []string) int {
+	var profileID, format string
+	var target time.Duration
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.StringVar(&profileID, "profile", scrambler.DefaultProfile, "KDF profile to time: "+profileIDs())
+	fs.DurationVar(&target, "target", 0, "propose a custom profile whose key derivation takes about `duration` (e.g. 30m)")
+	fs.StringVar(&format, "format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "bench: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+	profile, ok := scrambler.LookupProfile(profileID)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bench: unknown KDF profile %q\n", profileID)
+		return exitUsage
+	}
+	if format != "text" && format != "json" {
+		fmt.Fprintf(os.Stderr, "bench: unknown format %q\n", format)
+		return exitUsage
+	}
+	if target < 0 {
+		fmt.Fprintln(os.Stderr, "bench: -target must not be negative")
+		return exitUsage
+	}
+	if err := checkMemory(profile); err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		return exitError
+	}
+
+	if format == "text" {
+		fmt.Printf("Timing %s\n", describeProfile(profile))
+	}
+	b, err := scrambler.MeasureKDF(context.Background(), profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		return exitError
+	}
+	sha3, argon2 := b.Estimate()
+	result := benchResult{
+		Profile:       profile.ID,
+		SHA3Seconds:   sha3.Seconds(),
+		Argon2Seconds: argon2.Seconds(),
+		TotalSeconds:  (sha3 + argon2).Seconds(),
+	}
+	var proposed scrambler.KDFProfile
+	if target > 0 {
+		proposed, err = b.Calibrate(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			return exitError
+		}
+		result.Target = target.Seconds()
+		result.Proposed = proposed.ID
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+	fmt.Printf("SHA3-256 chain: %d rounds in %s, about %s for the profile\n",
+		b.SHA3Rounds, b.SHA3Time.Round(time.Millisecond), formatDuration(sha3))
+	fmt.Printf("Argon2id:       %s per pass plus %s setup, about %s for the profile\n",
+		b.Argon2Pass.Round(time.Millisecond), b.Argon2Setup.Round(time.Millisecond), formatDuration(argon2))
+	fmt.Printf("Estimated key derivation time: %s\n", formatDuration(sha3+argon2))
+	if target > 0 {
+		fmt.Printf("\nProposed profile for about %s:\n  %s\n", formatDuration(target), describeProfile(proposed))
+		fmt.Printf("\nScramble with -profile %s\n"+
+			"and write the profile ID down with the backup: it is needed, exactly as\n"+
+			"printed, to recover the words.\n", proposed.ID)
+	}
+	return exitOK
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"walletscrambler/internal/secmem"
+	"walletscrambler/internal/sysinfo"
+	"walletscrambler/scrambler"
+)
+
+// maxInputLen is the most wallet word input read from standard input.
+const maxInputLen = 64 * 1024
+
+// Process exit codes.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitInterrupted = 130
+	exitTerminated  = 143
+)
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		return interactive(false)
+	}
+	switch args[0] {
+	case "-strict", "--strict":
+		if len(args) > 1 {
+			fmt.Fprintf(stderr, "unexpected argument %q\n", args[1])
+			return exitUsage
+		}
+		return interactive(true)
+	case "scramble":
+		return runTransform(args[0], args[1:], false, stdin, stdout, stderr)
+	case "unscramble":
+		return runTransform(args[0], args[1:], true, stdin, stdout, stderr)
+	case "batch":
+		return runBatch(args[1:], stdin, stdout, stderr)
+	case "rekey":
+		return runRekey(args[1:], stdin, stdout, stderr)
+	case "bench":
+		return runBench(args[1:])
+	case "audit":
+		return runAudit(args[1:])
+	case "selftest":
+		return runSelfTest(args[1:])
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage:
+  wallet-scrambler [-strict]          run interactively; -strict refuses to run
+                                      unless the environment audit passes
+  wallet-scrambler scramble [flags]
+  wallet-scrambler unscramble [flags]
+  wallet-scrambler rekey [flags]      change the password, salt or KDF profile
+  wallet-scrambler batch [flags]      scramble many labelled wallets at once
+  wallet-scrambler bench [flags]      time the key derivation on this machine
+  wallet-scrambler audit [-strict]    check that this machine is air-gapped
+  wallet-scrambler selftest [-quick]  verify this binary against test vectors
+
+Without -password-file the password is read from the first line of standard
+input, and rekey reads the new password and its confirmation from the two
+lines after it. With -salt-source dice or coin the throws are read from the
+next line. Without -words the wallet words are read from the rest of
+standard input. Run
+"wallet-scrambler scramble -h" for the list of flags.
+
+scramble, unscramble, rekey and batch run the environment audit first and
+report the checks that did not pass on standard error. They refuse to run
+when a check fails unless -ignore-audit is given, and with -strict when any
+check does not pass.
+
+Exit status is 0 on success, 1 on error, 2 on invalid usage and 130 or 143
+when interrupted or terminated.
+`)
+}
+
+// noLabelWarning is printed when a wallet is scrambled without a label.
+const noLabelWarning = "warning: no -label given; wallets scrambled with the same password and salt " +
+	"share a key, and XORing their scrambled words reveals the XOR of the wallets"
+
+// checkLabel rejects a wallet label with leading or trailing whitespace.
+// Interactive and batch entry trim labels, so a wallet scrambled with such a
+// label could not be recovered there.
+func checkLabel(name, label string) error {
+	if label != strings.TrimSpace(label) {
+		return fmt.Errorf("%s %q must not start or end with whitespace", name, label)
+	}
+	return nil
+}
+
+// transformOptions holds the flags shared by scramble and unscramble.
+type transformOptions struct {
+	mode         scrambler.Mode
+	profile      scrambler.KDFProfile
+	label        string
+	words        string
+	salt         string
+	saltCount    int
+	saltSource   saltSourceOptions
+	count        int
+	checkWords   int
+	check        string
+	passwordFile string
+	format       string
+	progress     string
+	audit        auditOptions
+
+	duress            bool
+	decoyWords        string
+	decoyPasswordFile string
+}
+
+type transformResult struct {
+	Mode    string       `json:"mode"`
+	Profile string       `json:"profile"`
+	Label   string       `json:"label,omitempty"`
+	Salt    []string     `json:"salt,omitempty"`
+	Words   []string     `json:"words,omitempty"`
+	Check   []string     `json:"check,omitempty"`
+	Slots   []resultSlot `json:"slots,omitempty"`
+}
+
+// resultSlot is one slot of a duress backup.
+type resultSlot struct {
+	Words []string `json:"words"`
+	Check []string `json:"check"`
+}
+
+func runTransform(name string, args []string, recover bool, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts transformOptions
+	var modeName, profileID string
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&modeName, "mode", scrambler.ModeWords.Name(), "how wallet words are interpreted: "+modeNames())
+	fs.StringVar(&profileID, "profile", scrambler.DefaultProfile, "KDF profile: "+profileIDs())
+	fs.StringVar(&opts.label, "label", "", "wallet label mixed into the key, such as a name or an index")
+	fs.StringVar(&opts.words, "words", "", "wallet words separated by spaces or commas (default: read from stdin)")
+	fs.StringVar(&opts.salt, "salt", "", "salt words separated by spaces or commas")
+	if !recover {
+		fs.IntVar(&opts.saltCount, "salt-count", 0, "number of salt words to generate when -salt is not given")
+		opts.saltSource.register(fs, "-salt-count")
+	}
+	fs.IntVar(&opts.count, "count", 0, "expected number of wallet words (0 accepts any valid count)")
+	if recover {
+		fs.StringVar(&opts.check, "check", "", "check words written down when scrambling, to detect a wrong password or salt")
+	} else {
+		fs.IntVar(&opts.checkWords, "check-words", 0, fmt.Sprintf("number of check words to emit for detecting a wrong password (0 to %d)", scrambler.MaxCheckWords))
+	}
+	fs.StringVar(&opts.passwordFile, "password-file", "", "read the password from the first line of `file`")
+	if recover {
+		fs.BoolVar(&opts.duress, "duress", false, "the words and check words are the two slots of a duress backup, one after the other")
+	} else {
+		fs.BoolVar(&opts.duress, "duress", false, "write a two-slot duress backup")
+		fs.StringVar(&opts.decoyWords, "decoy-words", "", "decoy wallet words for the second slot of a duress backup (default: random words)")
+		fs.StringVar(&opts.decoyPasswordFile, "decoy-password-file", "", "read the duress password from the first line of `file` (default: the line after the password on stdin)")
+	}
+	fs.StringVar(&opts.format, "format", "text", "output format: text or json")
+	fs.StringVar(&opts.progress, "progress", "auto", "key derivation progress on stderr: "+strings.Join(progressFormats, ", "))
+	opts.audit.register(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "%s: unexpected argument %q\n", name, fs.Arg(0))
+		return exitUsage
+	}
+	mode, ok := scrambler.ModeByName(modeName)
+	if !ok {
+		fmt.Fprintf(stderr, "%s: unknown mode %q\n", name, modeName)
+		return exitUsage
+	}
+	opts.mode = mode
+	profile, ok := scrambler.LookupProfile(profileID)
+	if !ok {
+		fmt.Fprintf(stderr, "%s: unknown KDF profile %q\n", name, profileID)
+		return exitUsage
+	}
+	opts.profile = profile
+	if err := checkMemory(profile); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitError
+	}
+	if opts.format != "text" && opts.format != "json" {
+		fmt.Fprintf(stderr, "%s: unknown format %q\n", name, opts.format)
+		return exitUsage
+	}
+	if !validProgressFormat(opts.progress) {
+		fmt.Fprintf(stderr, "%s: unknown progress format %q\n", name, opts.progress)
+		return exitUsage
+	}
+	if err := checkLabel("-label", opts.label); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitUsage
+	}
+	if opts.salt != "" && opts.saltCount > 0 {
+		fmt.Fprintf(stderr, "%s: -salt and -salt-count are mutually exclusive\n", name)
+		return exitUsage
+	}
+	if opts.saltCount < 0 || opts.saltCount > scrambler.MaxSaltWords {
+		fmt.Fprintf(stderr, "%s: -salt-count must be between 0 and %d\n", name, scrambler.MaxSaltWords)
+		return exitUsage
+	}
+	if !recover {
+		if err := opts.saltSource.check("-salt-count", opts.saltCount); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			return exitUsage
+		}
+	}
+	if opts.decoyWords != "" && !opts.duress {
+		fmt.Fprintf(stderr, "%s: -decoy-words requires -duress\n", name)
+		return exitUsage
+	}
+	if opts.decoyPasswordFile != "" && opts.decoyWords == "" {
+		fmt.Fprintf(stderr, "%s: -decoy-password-file requires -decoy-words\n", name)
+		return exitUsage
+	}
+	if opts.duress && opts.checkWords != 0 && opts.checkWords < scrambler.MinDuressCheckWords {
+		fmt.Fprintf(stderr, "%s: a duress backup needs at least %d check words\n", name, scrambler.MinDuressCheckWords)
+		return exitUsage
+	}
+	if opts.checkWords < 0 || opts.checkWords > scrambler.MaxCheckWords {
+		fmt.Fprintf(stderr, "%s: -check-words must be between 0 and %d\n", name, scrambler.MaxCheckWords)
+		return exitUsage
+	}
+
+	if err := auditCommand(name, opts.audit, stderr); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitError
+	}
+	if err := transform(opts, recover, stdin, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return exitError
+	}
+	return exitOK
+}
+
+func transform(opts transformOptions, recover bool, in io.Reader, stdout, stderr io.Writer) error {
+	s := scrambler.New(scrambler.WithMode(opts.mode), scrambler.WithProfile(opts.profile), scrambler.WithLabel(opts.label),
+		scrambler.WithProgress(progressReporter(stderr, opts.progress)))
+	stdin := newPrompter(in, stderr)
+	defer stdin.wipe()
+
+	password, err := readPassword(opts.passwordFile, stdin, "Password: ")
+	if err != nil {
+		return err
+	}
+	if !recover && isWeakPassword(password) {
+		fmt.Fprintln(stderr, "warning: the password is weak")
+	}
+	if !recover && opts.label == "" {
+		fmt.Fprintln(stderr, noLabelWarning)
+	}
+	var decoyPassword []byte
+	if opts.decoyWords != "" {
+		decoyPassword, err = readPassword(opts.decoyPasswordFile, stdin, "Duress password: ")
+		if err != nil {
+			return fmt.Errorf("duress password: %w", err)
+		}
+	}
+	saltWords := splitWords(opts.salt, s.Wordlist())
+	if opts.saltCount > 0 {
+		if saltWords, err = opts.saltSource.generate(stdin, s.Wordlist(), opts.saltCount); err != nil {
+			return err
+		}
+	}
+
+	var walletWords []string
+	if opts.words != "" {
+		walletWords = splitWords(opts.words, s.Wordlist())
+	} else {
+		data := secmem.New(maxInputLen)
+		_, err := data.ReadFrom(in)
+		walletWords = internWords(data.Bytes(), s.Wordlist())
+		data.Destroy()
+		if err != nil {
+			return fmt.Errorf("reading wallet words: %w", err)
+		}
+	}
+	defer clearWords(walletWords)
+	decoyWords := splitWords(opts.decoyWords, s.Wordlist())
+	defer clearWords(decoyWords)
+
+	check := splitWords(opts.check, s.Wordlist())
+	for i, word := range check {
+		if !s.Wordlist().Contains(word) {
+			return fmt.Errorf("check word %d (%q) is not in the wordlist", i+1, word)
+		}
+	}
+
+	if opts.duress {
+		if recover {
+			return unscrambleDuress(stdout, opts, s, password, saltWords, walletWords, check)
+		}
+		return scrambleDuress(stdout, opts, s, password, decoyPassword, saltWords, walletWords, decoyWords)
+	}
+
+	if opts.count > 0 && len(walletWords) != opts.count {
+		return fmt.Errorf("expected %d wallet words, got %d", opts.count, len(walletWords))
+	}
+	if err := s.ValidateWords(walletWords); err != nil {
+		return err
+	}
+	if len(check) > scrambler.MaxCheckWords {
+		return fmt.Errorf("got %d check words, expected at most %d", len(check), scrambler.MaxCheckWords)
+	}
+	key, err := s.DeriveKey(password, saltWords)
+	if err != nil {
+		return err
+	}
+	defer key.Wipe()
+
+	result := transformResult{Mode: opts.mode.Name(), Profile: opts.profile.ID, Label: opts.label}
+	if recover {
+		if len(check) > 0 {
+			if err := key.VerifyCheckWords(walletWords, check); err != nil {
+				return err
+			}
+		}
+		result.Words, err = key.Unscramble(walletWords)
+	} else {
+		result.Salt = saltWords
+		result.Words, err = key.Scramble(walletWords)
+		if err == nil && opts.checkWords > 0 {
+			result.Check, err = key.CheckWords(result.Words, opts.checkWords)
+		}
+	}
+	defer clearWords(result.Words)
+	if err != nil {
+		return err
+	}
+	return writeResult(stdout, opts.format, result)
+}
+
+// scrambleDuress writes a two-slot duress backup of walletWords. The second
+// slot holds decoyWords under decoyPassword, or random words when no decoy
+// is given.
+func scrambleDuress(stdout io.Writer, opts transformOptions, s *scrambler.Scrambler, password, decoyPassword []byte, saltWords, walletWords, decoyWords []string) error {
+	if opts.count > 0 && len(walletWords) != opts.count {
+		return fmt.Errorf("expected %d wallet words, got %d", opts.count, len(walletWords))
+	}
+	if err := s.ValidateWords(walletWords); err != nil {
+		return err
+	}
+	if len(decoyWords) > 0 {
+		if err := s.ValidateWords(decoyWords); err != nil {
+			return fmt.Errorf("decoy: %w", err)
+		}
+		if len(decoyWords) != len(walletWords) {
+			return fmt.Errorf("got %d decoy words, expected %d like the wallet", len(decoyWords), len(walletWords))
+		}
+	}
+	checkWords := opts.checkWords
+	if checkWords == 0 {
+		checkWords = scrambler.MinDuressCheckWords
+	}
+
+	key, err := s.DeriveKey(password, saltWords)
+	if err != nil {
+		return err
+	}
+	defer key.Wipe()
+	var decoy *scrambler.Key
+	if len(decoyWords) > 0 {
+		if decoy, err = s.DeriveKey(decoyPassword, saltWords); err != nil {
+			return err
+		}
+		defer decoy.Wipe()
+	}
+	backup, err := scrambler.SealDuress(key, walletWords, decoy, decoyWords, checkWords)
+	if err != nil {
+		return err
+	}
+	result := transformResult{Mode: opts.mode.Name(), Profile: opts.profile.ID, Label: opts.label, Salt: saltWords}
+	for i := range backup.Slots {
+		result.Slots = append(result.Slots, resultSlot{Words: backup.Slots[i], Check: backup.Check[i]})
+	}
+	return writeResult(stdout, opts.format, result)
+}
+
+// unscrambleDuress recovers the slot of a duress backup that opens with
+// password. The words and check words of both slots are given one slot
+// after the other.
+func unscrambleDuress(stdout io.Writer, opts transformOptions, s *scrambler.Scrambler, password []byte, saltWords, walletWords, check []string) error {
+	backup, err := splitDuress(walletWords, check)
+	if err != nil {
+		return err
+	}
+	if opts.count > 0 && len(backup.Slots[0]) != opts.count {
+		return fmt.Errorf("expected %d wallet words per slot, got %d", opts.count, len(backup.Slots[0]))
+	}
+	for i, slot := range backup.Slots {
+		if err := s.ValidateWords(slot); err != nil {
+			return fmt.Errorf("slot %d: %w", i+1, err)
+		}
+	}
+
+	key, err := s.DeriveKey(password, saltWords)
+	if err != nil {
+		return err
+	}
+	defer key.Wipe()
+	words, err := key.OpenDuress(backup)
+	if err != nil {
+		return err
+	}
+	defer clearWords(words)
+	return writeResult(stdout, opts.format, transformResult{Mode: opts.mode.Name(), Profile: opts.profile.ID, Label: opts.label, Words: words})
+}
+
+// splitDuress divides the words and check words of a duress backup into its
+// two slots of equal size.
+func splitDuress(words, check []string) (*scrambler.DuressBackup, error) {
+	if len(words)%2 != 0 {
+		return nil, fmt.Errorf("a duress backup has two slots of equal size, got %d words", len(words))
+	}
+	if len(check)%2 != 0 || len(check)/2 < scrambler.MinDuressCheckWords || len(check)/2 > scrambler.MaxCheckWords {
+		return nil, fmt.Errorf("a duress backup has %d to %d check words per slot, got %d in total",
+			scrambler.MinDuressCheckWords, scrambler.MaxCheckWords, len(check))
+	}
+	var b scrambler.DuressBackup
+	n, c := len(words)/2, len(check)/2
+	b.Slots[0], b.Slots[1] = words[:n], words[n:]
+	b.Check[0], b.Check[1] = check[:c], check[c:]
+	return &b, nil
+}
+
+// readPassword reads the password from the first line of file, or from
+// stdin when no file is given. The password is wiped together with stdin.
+func readPassword(file string, stdin *prompter, prompt string) ([]byte, error) {
+	source := stdin
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		source = newPrompter(f, stdin.out)
+	} else if stdin.fd >= 0 {
+		fmt.Fprint(stdin.out, prompt)
+	}
+	password, err := source.secret()
+	if source != stdin {
+		// Hand the password over so it is wiped together with stdin.
+		stdin.secrets = append(stdin.secrets, source.secrets...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading password: %w", err)
+	}
+	if len(password) == 0 {
+		return nil, errors.New("empty password")
+	}
+	return password, nil
+}
+
+func modeNames() string {
+	names := make([]string, len(scrambler.Modes))
+	for i, mode := range scrambler.Modes {
+		names[i] = mode.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+func profileIDs() string {
+	profiles := scrambler.Profiles()
+	ids := make([]string, len(profiles))
+	for i, profile := range profiles {
+		ids[i] = profile.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+// memoryHeadroom is the memory a key derivation needs beyond Argon2id's own,
+// for the runtime and the rest of the process.
+const memoryHeadroom = 64 << 20
+
+// checkMemory returns an error if the machine does not have the memory to
+// finish a key derivation with profile, so it is refused before any time is
+// spent on the SHA3 chain. It passes where available memory is unknown.
+func checkMemory(profile scrambler.KDFProfile) error {
+	available, ok := sysinfo.AvailableMemory()
+	need := profile.MemoryRequired() + memoryHeadroom
+	if !ok || available >= need {
+		return nil
+	}
+	var fit []string
+	for _, p := range scrambler.Profiles() {
+		if p.MemoryRequired()+memoryHeadroom <= available {
+			fit = append(fit, p.ID)
+		}
+	}
+	hint := "no profile fits"
+	if len(fit) > 0 {
+		hint = "new backups can use " + strings.Join(fit, ", ")
+	}
+	return fmt.Errorf("KDF profile %s needs about %d MiB of memory but only %d MiB is available; "+
+		"close other programs or use a machine with more memory (%s)", profile.ID, need>>20, available>>20, hint)
+}
+
+// describeProfile summarises the parameters of profile on one line.
+func describeProfile(profile scrambler.KDFProfile) string {
+	return fmt.Sprintf("%s: %d SHA3 rounds, Argon2id %d MiB x %d passes, %d threads",
+		profile.ID, profile.SHA3Rounds, profile.Argon2Memory/1024, profile.Argon2Time, profile.Argon2Threads)
+}
+
+// splitWords is internWords for words given on the command line.
+func splitWords(s string, list scrambler.Wordlist) []string {
+	return internWords([]byte(s), list)
+}
+
+func isWordSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+func writeResult(w io.Writer, format string, result transformResult) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	if len(result.Salt) > 0 {
+		fprintBeautifully(w, "Salt:", result.Salt)
+	}
+	if len(result.Words) > 0 {
+		fprintBeautifully(w, "Wallet Words:", result.Words)
+	}
+	if len(result.Check) > 0 {
+		fprintBeautifully(w, "Check Words:", result.Check)
+	}
+	for i, slot := range result.Slots {
+		fprintBeautifully(w, fmt.Sprintf("Slot %d Words:", i+1), slot.Words)
+		fprintBeautifully(w, fmt.Sprintf("Slot %d Check Words:", i+1), slot.Check)
+	}
+	fmt.Fprintf(w, "\nKDF profile: %s\n", result.Profile)
+	if result.Label != "" {
+		fmt.Fprintf(w, "Wallet label: %s\n", result.Label)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"walletscrambler/scrambler"
+)
+
+// testProfile makes the key derivation of the command tests cheap. It is
+// never used for a real backup.
+const testProfile = "test-cli"
+
+func TestMain(m *testing.M) {
+	if err := scrambler.RegisterProfile(scrambler.KDFProfile{
+		ID: testProfile, SHA3Rounds: 1, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1, KeyLen: 64,
+	}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+const testWords = "academic acid acne acquire acrobat activity actress adapt adequate adjust admit zero"
+
+// runCommand runs the command line args with stdin as standard input and
+// returns the exit status and what was written to standard output and
+// standard error.
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunExitStatus(t *testing.T) {
+	common := []string{"-profile", testProfile, "-progress", "none", "-ignore-audit", "-label", "savings"}
+	for _, tt := range []struct {
+		name   string
+		stdin  string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"unknown command", "", []string{"shuffle"}, exitUsage, `unknown command "shuffle"`},
+		{"unknown flag", "", []string{"scramble", "-shuffle"}, exitUsage, "flag provided but not defined: -shuffle"},
+		{"unexpected argument", "", []string{"scramble", "words"}, exitUsage, `scramble: unexpected argument "words"`},
+		{"unknown mode", "", []string{"unscramble", "-mode", "bip32"}, exitUsage, `unscramble: unknown mode "bip32"`},
+		{"unknown profile", "", []string{"rekey", "-profile", "v0"}, exitUsage, `rekey: unknown KDF profile "v0"`},
+		{"salt source without count", "", []string{"batch", "-salt-source", "dice"}, exitUsage, "batch: -salt-source dice requires -salt-count"},
+		{"label with whitespace", "", []string{"scramble", "-label", "savings "}, exitUsage, "must not start or end with whitespace"},
+		{"empty password", "\n", append([]string{"scramble", "-words", testWords}, common...), exitError, "scramble: empty password"},
+		{"unknown word", "Abcdefg1!\n", append([]string{"scramble", "-words", strings.Replace(testWords, "acid", "acidd", 1)}, common...), exitError, `"acidd"`},
+		{"wrong word count", "Abcdefg1!\n" + testWords + "\n", append([]string{"scramble", "-count", "20"}, common...), exitError, "expected 20 wallet words, got 12"},
+		{"wrong password", "Abcdefg2!\n", append([]string{"unscramble", "-words", testWords, "-check", "zero zero"}, common...), exitError, "password or salt incorrect"},
+	} {
+		code, stdout, stderr := runCommand(tt.stdin, tt.args...)
+		if code != tt.code || !strings.Contains(stderr, tt.stderr) {
+			t.Errorf("%s: exit status %d with %q, want %d with %q", tt.name, code, stderr, tt.code, tt.stderr)
+		}
+		if stdout != "" {
+			t.Errorf("%s: wrote %q to standard output", tt.name, stdout)
+		}
+	}
+}
+
+func TestScrambleRoundTrip(t *testing.T) {
+	common := []string{"-profile", testProfile, "-progress", "none", "-ignore-audit", "-label", "savings", "-format", "json"}
+	code, stdout, stderr := runCommand("Abcdefg1!\n"+testWords+"\n",
+		append([]string{"scramble", "-salt-count", "2", "-check-words", "2"}, common...)...)
+	if code != exitOK {
+		t.Fatalf("scramble: exit status %d: %s", code, stderr)
+	}
+	var scrambled transformResult
+	if err := json.Unmarshal([]byte(stdout), &scrambled); err != nil {
+		t.Fatalf("scramble: %v in %q", err, stdout)
+	}
+	if len(scrambled.Salt) != 2 || len(scrambled.Words) != 12 || len(scrambled.Check) != 2 {
+		t.Fatalf("scramble: got %d salt, %d wallet and %d check words, want 2, 12 and 2",
+			len(scrambled.Salt), len(scrambled.Words), len(scrambled.Check))
+	}
+	if scrambled.Profile != testProfile || scrambled.Label != "savings" {
+		t.Errorf("scramble: profile %q and label %q, want %q and %q", scrambled.Profile, scrambled.Label, testProfile, "savings")
+	}
+	if strings.Join(scrambled.Words, " ") == testWords {
+		t.Error("scramble returned the wallet words unchanged")
+	}
+
+	code, stdout, stderr = runCommand("Abcdefg1!\n"+strings.Join(scrambled.Words, " ")+"\n",
+		append([]string{"unscramble", "-salt", strings.Join(scrambled.Salt, " "), "-check", strings.Join(scrambled.Check, " ")}, common...)...)
+	if code != exitOK {
+		t.Fatalf("unscramble: exit status %d: %s", code, stderr)
+	}
+	var recovered transformResult
+	if err := json.Unmarshal([]byte(stdout), &recovered); err != nil {
+		t.Fatalf("unscramble: %v in %q", err, stdout)
+	}
+	if want := strings.Fields(testWords); !reflect.DeepEqual(recovered.Words, want) {
+		t.Errorf("unscramble = %q, want %q", recovered.Words, want)
+	}
+}
+
+func TestCheckLabel(t *testing.T) {
+	for _, tt := range []struct {
+		label string
+		ok    bool
+	}{
+		{"", true},
+		{"savings", true},
+		{"cold storage 2", true},
+		{"savings ", false},
+		{" savings", false},
+		{"savings\t", false},
+		{"savings\n", false},
+	} {
+		if err := checkLabel("-label", tt.label); (err == nil) != tt.ok {
+			t.Errorf("checkLabel(%q) = %v, want ok %v", tt.label, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+
+	"walletscrambler/scrambler"
+)
+
+// mixSeedSize is the number of bytes of the machine's random generator mixed
+// into salt words drawn from dice or coins.
+const mixSeedSize = 32
+
+// generateSalt draws count salt words from list, with the machine's random
+// generator or from dice rolls or coin flips the user types in, and tells
+// how many bits of entropy they add.
+func generateSalt(p *prompter, list scrambler.Wordlist, count int) ([]string, error) {
+	if count == 0 {
+		printStyled("\n{yellow}No salt words. The key only depends on the password.")
+		return nil, nil
+	}
+	printStyled("\n")
+	machine, err := choice(p, "Do you want this machine to pick the salt words, or draw them from dice or coins?", "Machine", "Physical", "M", "P")
+	if err != nil {
+		return nil, err
+	}
+	var saltWords []string
+	if machine {
+		if saltWords, err = randomSalt(list, count); err != nil {
+			return nil, fmt.Errorf("generating random index: %w", err)
+		}
+		printStyled("\n{green}Salt words generated.")
+	} else if saltWords, err = physicalSalt(p, list, count); err != nil {
+		return nil, err
+	}
+	printStyled(fmt.Sprintf("\n{green}The salt adds %d bits of entropy, %d per word.",
+		scrambler.SaltEntropy(list, count), list.BitsPerWord()))
+	return saltWords, nil
+}
+
+// physicalSalt reads count salt words as dice rolls or coin flips, one group
+// of throws per word, and optionally mixes them with the machine's random
+// generator.
+func physicalSalt(p *prompter, list scrambler.Wordlist, count int) ([]string, error) {
+	printStyled("\n")
+	dice, err := choice(p, "Do you want to roll dice or flip a coin?", "Dice", "Coin", "D", "C")
+	if err != nil {
+		return nil, err
+	}
+	source, how := scrambler.Coin, "Flip a coin %d times for salt word %d (H or T): "
+	if dice {
+		source, how = scrambler.Die, "Roll a die %d times for salt word %d (1-6): "
+	}
+	printStyled("\n")
+	mix, err := choice(p, "Do you want to mix the throws with this machine's random generator? "+
+		"The words are then as unpredictable as the better of the two, but cannot be checked against the throws.",
+		"Mix", "Throws only", "M", "T")
+	if err != nil {
+		return nil, err
+	}
+
+	n := source.OutcomesPerWord(list)
+	printStyled(fmt.Sprintf("\n{cyan}Each word takes %d throws. Throws that would make some words more likely\n", n))
+	printStyled("{cyan}than others are rejected; throw the whole group again when that happens.\n\n")
+	indices := make([]int, 0, count)
+	defer func() { clear(indices) }()
+	for i := 0; i < count; i++ {
+		for {
+			fmt.Printf(how, n, i+1)
+			line, err := p.line()
+			if err != nil {
+				return nil, err
+			}
+			outcomes, err := source.Parse(line)
+			if err != nil {
+				fmt.Println("Invalid input: " + err.Error() + ".")
+				continue
+			}
+			index, err := source.WordIndex(list, outcomes)
+			clear(outcomes)
+			if errors.Is(err, scrambler.ErrRejected) {
+				printStyled("{yellow}These throws are rejected to keep the words unbiased. Throw again.\n")
+				continue
+			}
+			if err != nil {
+				fmt.Println("Invalid input: " + err.Error() + ".")
+				continue
+			}
+			indices = append(indices, index)
+			break
+		}
+	}
+
+	saltWords, err := saltFromThrows(list, indices, mix)
+	if err != nil {
+		return nil, err
+	}
+	if mix {
+		printStyled("\n{green}Salt words drawn from your throws mixed with the machine's random generator.")
+	} else {
+		printStyled("\n{green}Salt words drawn from your throws.")
+	}
+	return saltWords, nil
+}
+
+// saltFromThrows returns the salt words of the word indices drawn from
+// throws, mixed with the machine's random generator if mix is set, and wipes
+// indices.
+func saltFromThrows(list scrambler.Wordlist, indices []int, mix bool) ([]string, error) {
+	defer clear(indices)
+	if !mix {
+		saltWords := make([]string, len(indices))
+		for i, index := range indices {
+			saltWords[i] = list[index]
+		}
+		return saltWords, nil
+	}
+	seed := make([]byte, mixSeedSize)
+	defer clear(seed)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("generating random seed: %w", err)
+	}
+	return scrambler.MixSalt(list, indices, seed), nil
+}
+
+// saltSourceOptions holds the flags that choose where the salt words
+// generated by a scripted command come from.
+type saltSourceOptions struct {
+	source string // random, dice or coin
+	mix    bool   // mix the throws with the machine's random generator
+}
+
+// register adds the salt source flags to fs. countFlag names the flag that
+// sets the number of salt words to generate.
+func (o *saltSourceOptions) register(fs *flag.FlagSet, countFlag string) {
+	fs.StringVar(&o.source, "salt-source", "random", "where the "+countFlag+" salt words come from: random, dice or coin; "+
+		"dice rolls and coin flips are read from the line of stdin after the passwords")
+	fs.BoolVar(&o.mix, "salt-mix", false, "mix the dice rolls or coin flips with the machine's random generator")
+}
+
+// check validates the salt source flags for count salt words.
+func (o saltSourceOptions) check(countFlag string, count int) error {
+	switch o.source {
+	case "random":
+		if o.mix {
+			return errors.New("-salt-mix requires -salt-source dice or coin")
+		}
+	case "dice", "coin":
+		if count == 0 {
+			return fmt.Errorf("-salt-source %s requires %s", o.source, countFlag)
+		}
+	default:
+		return fmt.Errorf("unknown salt source %q", o.source)
+	}
+	return nil
+}
+
+// generate draws count salt words from list with the chosen source and
+// reports their entropy on the output of stdin. Dice rolls and coin flips
+// are read from the next line of stdin.
+func (o saltSourceOptions) generate(stdin *prompter, list scrambler.Wordlist, count int) ([]string, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	var saltWords []string
+	if o.source == "random" {
+		var err error
+		if saltWords, err = randomSalt(list, count); err != nil {
+			return nil, fmt.Errorf("generating salt: %w", err)
+		}
+	} else {
+		source := scrambler.Die
+		if o.source == "coin" {
+			source = scrambler.Coin
+		}
+		if stdin.fd >= 0 {
+			fmt.Fprintf(stdin.out, "Throws of a %s for %d salt words, %d per word: ", source.Name, count, source.OutcomesPerWord(list))
+		}
+		line, err := stdin.line()
+		if err != nil {
+			return nil, fmt.Errorf("reading salt throws: %w", err)
+		}
+		outcomes, err := source.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("salt throws: %w", err)
+		}
+		indices, err := throwIndices(source, list, outcomes, count)
+		clear(outcomes)
+		if err != nil {
+			return nil, fmt.Errorf("salt throws: %w", err)
+		}
+		if saltWords, err = saltFromThrows(list, indices, o.mix); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(stdin.out, "The salt adds %d bits of entropy, %d per word.\n", scrambler.SaltEntropy(list, count), list.BitsPerWord())
+	return saltWords, nil
+}
+
+// throwIndices turns outcomes into count word indices of list, one group of
+// source.OutcomesPerWord outcomes after the other. Groups that are rejected
+// to keep the words unbiased are skipped, and outcomes left over once count
+// words are drawn are ignored.
+func throwIndices(source scrambler.Source, list scrambler.Wordlist, outcomes []int, count int) ([]int, error) {
+	n := source.OutcomesPerWord(list)
+	indices := make([]int, 0, count)
+	rejected := 0
+	for len(indices) < count && len(outcomes) >= n {
+		index, err := source.WordIndex(list, outcomes[:n])
+		outcomes = outcomes[n:]
+		if errors.Is(err, scrambler.ErrRejected) {
+			rejected++
+			continue
+		}
+		if err != nil {
+			clear(indices)
+			return nil, err
+		}
+		indices = append(indices, index)
+	}
+	if len(indices) < count {
+		clear(indices)
+		return nil, fmt.Errorf("not enough throws: need %d accepted groups of %d throws, got %d accepted and %d rejected",
+			count, n, len(indices), rejected)
+	}
+	return indices, nil
+}
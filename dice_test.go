@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"walletscrambler/scrambler"
+)
+
+func TestSaltFromThrowsWipesIndices(t *testing.T) {
+	for _, mix := range []bool{false, true} {
+		indices := []int{1, 2, 3, 1023}
+		words, err := saltFromThrows(scrambler.SLIP39, indices, mix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(words) != 4 {
+			t.Errorf("mix %v: got %d words, want 4", mix, len(words))
+		}
+		if !mix {
+			want := []string{scrambler.SLIP39[1], scrambler.SLIP39[2], scrambler.SLIP39[3], scrambler.SLIP39[1023]}
+			if !reflect.DeepEqual(words, want) {
+				t.Errorf("got %q, want %q", words, want)
+			}
+		}
+		if !reflect.DeepEqual(indices, []int{0, 0, 0, 0}) {
+			t.Errorf("mix %v: indices not wiped: %v", mix, indices)
+		}
+	}
+}
+
+func TestThrowIndices(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		source scrambler.Source
+		throws string
+		count  int
+		want   []int
+		err    string
+	}{
+		{"dice", scrambler.Die, "1111 1112", 2, []int{0, 1}, ""},
+		{"rejected group skipped", scrambler.Die, "6666 1112", 1, []int{1}, ""},
+		{"leftover throws ignored", scrambler.Die, "1111 1112 34", 1, []int{0}, ""},
+		{"coin", scrambler.Coin, "HHHHHHHHHT TTTTTTTTTT", 2, []int{1, 1023}, ""},
+		{"too few", scrambler.Die, "1111 111", 2, nil, "got 1 accepted and 0 rejected"},
+		{"too few after rejection", scrambler.Die, "6666 1111", 2, nil, "got 1 accepted and 1 rejected"},
+	} {
+		outcomes, err := tt.source.Parse(tt.throws)
+		if err != nil {
+			t.Fatalf("%s: Parse: %v", tt.name, err)
+		}
+		got, err := throwIndices(tt.source, scrambler.SLIP39, outcomes, tt.count)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: throwIndices error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: throwIndices = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSaltSourceCheck(t *testing.T) {
+	for _, tt := range []struct {
+		opts  saltSourceOptions
+		count int
+		err   string
+	}{
+		{saltSourceOptions{source: "random"}, 0, ""},
+		{saltSourceOptions{source: "random"}, 4, ""},
+		{saltSourceOptions{source: "dice", mix: true}, 4, ""},
+		{saltSourceOptions{source: "coin"}, 4, ""},
+		{saltSourceOptions{source: "dice"}, 0, "-salt-source dice requires -salt-count"},
+		{saltSourceOptions{source: "random", mix: true}, 4, "-salt-mix requires"},
+		{saltSourceOptions{source: "urandom"}, 4, `unknown salt source "urandom"`},
+	} {
+		err := tt.opts.check("-salt-count", tt.count)
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("check(%+v, %d) = %v, want %q", tt.opts, tt.count, err, tt.err)
+		}
+	}
+}
@@ -1,7 +1,10 @@
-module walletscrambler
-
-go 1.23.4
-
-require golang.org/x/crypto v0.30.0
-
-require golang.org/x/sys v0.28.0 // indirect
+module walletscrambler
+
+go 1.23.4
+
+require (
+	golang.org/x/crypto v0.30.0
+	golang.org/x/term v0.27.0
+)
+
+require golang.org/x/sys v0.28.0
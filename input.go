@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"walletscrambler/internal/secmem"
+	"walletscrambler/scrambler"
+)
+
+// maxLineLen is the longest answer accepted at a prompt.
+const maxLineLen = 1024
+
+// errInputClosed is returned when the input ends before a prompt has been
+// answered.
+var errInputClosed = errors.New("input closed")
+
+// errLineTooLong is returned when an answer exceeds maxLineLen.
+var errLineTooLong = errors.New("input line too long")
+
+// prompter reads answers one line at a time. Input is read byte by byte
+// straight into locked memory so nothing is left behind in a buffer that
+// cannot be wiped, and every secret handed out is remembered until wipe is
+// called.
+type prompter struct {
+	in  io.Reader
+	out io.Writer // receives the echo of hidden answers
+	fd  int       // terminal file descriptor of in, or -1
+
+	// hideWords masks wallet and salt words like passwords.
+	hideWords bool
+
+	secrets []*secmem.Buffer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: in, out: out, fd: terminalFd(in)}
+}
+
+// readLine reads one line without its terminator. A final line without a
+// newline is returned as is; only when nothing at all could be read does it
+// return errInputClosed. The caller must destroy the returned buffer.
+func (p *prompter) readLine() (*secmem.Buffer, error) {
+	line := secmem.New(maxLineLen)
+	var b [1]byte
+	for {
+		n, err := p.in.Read(b[:])
+		if n == 1 {
+			if b[0] == '\n' {
+				if bytes.HasSuffix(line.Bytes(), []byte{'\r'}) {
+					line.Truncate(line.Len() - 1)
+				}
+				return line, nil
+			}
+			if line.AppendByte(b[0]) != nil {
+				line.Destroy()
+				return nil, errLineTooLong
+			}
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			if line.Len() > 0 {
+				return line, nil
+			}
+			err = errInputClosed
+		}
+		line.Destroy()
+		return nil, err
+	}
+}
+
+// line reads a non-secret answer with surrounding whitespace removed.
+func (p *prompter) line() (string, error) {
+	raw, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	defer raw.Destroy()
+	return string(bytes.TrimSpace(raw.Bytes())), nil
+}
+
+// secret reads a secret answer without echoing it and with surrounding
+// whitespace removed. The returned bytes stay valid until wipe is called.
+func (p *prompter) secret() ([]byte, error) {
+	raw, err := p.readHidden()
+	if err != nil {
+		return nil, err
+	}
+	p.secrets = append(p.secrets, raw)
+	return bytes.TrimSpace(raw.Bytes()), nil
+}
+
+// word reads a wallet or salt word and returns the wordlist's own copy of it,
+// so the typed text can be wiped straight away. Case and surrounding
+// whitespace are ignored, a unique prefix of at least scrambler.MinPrefix
+// letters stands for the whole word, and on a terminal Tab completes the
+// word typed so far. When a prefix was typed with words visible, the
+// resolved word is echoed for the user to confirm.
+//
+// When the answer is not in the list, word is empty and hint says what the
+// user may have meant. Typo suggestions are left out while words are
+// hidden, as they would show what was typed.
+func (p *prompter) word(list scrambler.Wordlist) (word, hint string, err error) {
+	complete := func(typed []byte) string {
+		return completeWord(list, string(bytes.TrimSpace(typed)))
+	}
+	var raw *secmem.Buffer
+	switch {
+	case p.fd < 0:
+		raw, err = p.readLine()
+	case p.hideWords:
+		raw, err = p.readRaw(passwordMask, complete)
+	default:
+		raw, err = p.readRaw(0, complete)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	defer raw.Destroy()
+	typed := scrambler.NormalizeWord(raw.Bytes())
+	word, ok := list.Resolve(string(typed))
+	if ok {
+		if p.fd >= 0 && !p.hideWords && len(typed) < len(word) {
+			printStyled("{green}  -> " + word + "\n")
+		}
+		return word, "", nil
+	}
+	return "", wordHint(list, string(typed), p.hideWords), nil
+}
+
+// wordHint explains why typed is not in list: it is from the other standard
+// wordlist, or, unless hide is set, it looks like a typo of some word.
+func wordHint(list scrambler.Wordlist, typed string, hide bool) string {
+	if other, ok := list.OtherWordlist(typed); ok {
+		return fmt.Sprintf("That is a %s word, but these words are from the %s wordlist.", other, list.Name())
+	}
+	if hide {
+		return ""
+	}
+	suggestions := list.Suggest(typed)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return "Did you mean " + strings.Join(suggestions, " or ") + "?"
+}
+
+// completeWord returns what Tab appends to prefix: the rest of the only word
+// starting with it, or else the longest run of letters all the words
+// starting with it share.
+func completeWord(list scrambler.Wordlist, prefix string) string {
+	matches := list.Complete(prefix)
+	if len(matches) == 0 {
+		return ""
+	}
+	common := matches[0]
+	for _, m := range matches[1:] {
+		n := 0
+		for n < len(common) && n < len(m) && common[n] == m[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return common[len(prefix):]
+}
+
+// wipe destroys every secret read so far.
+func (p *prompter) wipe() {
+	for _, s := range p.secrets {
+		s.Destroy()
+	}
+	p.secrets = nil
+}
+
+// internWords splits data into words separated by spaces or commas and
+// resolves them like interactive entry: case is ignored and a unique prefix
+// of at least scrambler.MinPrefix letters stands for the whole word. Every
+// known word is replaced with the wordlist's own copy, so data can be wiped
+// afterwards; unknown words are kept in lower case for the error message.
+func internWords(data []byte, list scrambler.Wordlist) []string {
+	fields := bytes.FieldsFunc(data, isWordSeparator)
+	words := make([]string, len(fields))
+	for i, field := range fields {
+		typed := scrambler.NormalizeWord(field)
+		if word, ok := list.Resolve(string(typed)); ok {
+			words[i] = word
+		} else {
+			words[i] = string(typed)
+		}
+	}
+	return words
+}
+
+// clearWords drops the references to words so the order of a plaintext
+// backup does not linger in memory.
+func clearWords(words []string) {
+	for i := range words {
+		words[i] = ""
+	}
+}
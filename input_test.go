@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"walletscrambler/internal/secmem"
+	"walletscrambler/scrambler"
+)
+
+func TestInternWords(t *testing.T) {
+	for _, tt := range []struct {
+		data string
+		want []string
+	}{
+		{"academic acid", []string{"academic", "acid"}},
+		{" Academic,ACID\n", []string{"academic", "acid"}},
+		{"acad acid", []string{"academic", "acid"}}, // unique prefix
+		{"aca acid", []string{"aca", "acid"}},       // too short to resolve
+		{"Acadmic", []string{"acadmic"}},            // unknown, kept for the error
+		{"", []string{}},
+	} {
+		if got := internWords([]byte(tt.data), scrambler.SLIP39); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("internWords(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestReadLine(t *testing.T) {
+	failure := errors.New("device removed")
+	long := strings.Repeat("a", maxLineLen)
+	for _, tt := range []struct {
+		name  string
+		in    io.Reader
+		lines []string
+		err   error
+	}{
+		{"empty", strings.NewReader(""), nil, errInputClosed},
+		{"lines", strings.NewReader("one\ntwo\n"), []string{"one", "two"}, errInputClosed},
+		{"final line without newline", strings.NewReader("one\ntwo"), []string{"one", "two"}, errInputClosed},
+		{"carriage return", strings.NewReader("one\r\n\r\n"), []string{"one", ""}, errInputClosed},
+		{"one byte at a time", iotest.OneByteReader(strings.NewReader("one\ntwo")), []string{"one", "two"}, errInputClosed},
+		{"data with end of file", iotest.DataErrReader(strings.NewReader("one")), []string{"one"}, errInputClosed},
+		{"longest line", strings.NewReader(long + "\n"), []string{long}, errInputClosed},
+		{"line too long", strings.NewReader(long + "a\n"), nil, errLineTooLong},
+		{"error", iotest.ErrReader(failure), nil, failure},
+		{"error after a line", io.MultiReader(strings.NewReader("one\ntw"), iotest.ErrReader(failure)), []string{"one"}, failure},
+	} {
+		p := newPrompter(tt.in, io.Discard)
+		var lines []string
+		var err error
+		for {
+			var line *secmem.Buffer
+			if line, err = p.readLine(); err != nil {
+				break
+			}
+			lines = append(lines, string(line.Bytes()))
+			line.Destroy()
+		}
+		if !errors.Is(err, tt.err) || !reflect.DeepEqual(lines, tt.lines) {
+			t.Errorf("%s: readLine = %q, %v, want %q, %v", tt.name, lines, err, tt.lines, tt.err)
+		}
+	}
+}
@@ -0,0 +1,711 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"walletscrambler/internal/secmem"
+	"walletscrambler/internal/sysinfo"
+	"walletscrambler/scrambler"
+)
+
+// interactive walks the user through scrambling or recovering a wallet with
+// prompts and returns the process exit code. Secrets read from the user are
+// wiped before it returns, including when the input is closed early. With
+// strict set it refuses to start unless every environment audit check
+// passes.
+func interactive(strict bool) int {
+	findings := sysinfo.Audit()
+	if strict && sysinfo.Worst(findings) != sysinfo.Pass {
+		printStyled("{bold}{cyan}Environment audit:\n")
+		printAudit(findings)
+		printStyled("\n{red}Refusing to run: not every check passed (-strict).\n")
+		return exitError
+	}
+	p := newPrompter(os.Stdin, os.Stdout)
+	defer p.wipe()
+	enterAltScreen()
+	summary, err := interactiveSession(p, findings)
+	leaveAltScreen()
+	if err != nil {
+		if errors.Is(err, errEnvironmentRejected) {
+			printStyled("\n{yellow}Quit at the environment audit. Nothing was entered.\n")
+			return exitError
+		} else if errors.Is(err, errInputClosed) {
+			printStyled("\n\n{red}Input closed, aborting.\n")
+		} else if errors.Is(err, errInterrupted) {
+			printStyled("\n{red}Interrupted, aborting.\n")
+			return exitInterrupted
+		} else {
+			printStyled("\n{red}Error: " + err.Error() + "\n")
+		}
+		return exitError
+	}
+	printStyled("\n{green}" + summary + "\n")
+	return exitOK
+}
+
+func interactiveSession(p *prompter, findings []sysinfo.Finding) (summary string, err error) {
+	printStyled("\n\n{cyan}{bold}{underline}Welcome to the wallet word scrambler\n\n")
+	printStyled("A password and salt will be use to scramble your backup words\n")
+	printStyled("The SLIP39 (1024 words) and BIP39 (2048 words) English wordlists are supported\n\n")
+	printStyled("{red}Warning:\n")
+	printStyled("{yellow}This program is meant to run on a fresh formated and air gapped machine\n")
+	printStyled("{yellow}It is not safe to run it on a machine connected to any kind of network\n")
+	printStyled("{yellow}Though we save nothing - {bold}secure wipe{reset}{yellow} your machine after use\n\n")
+
+	if err := confirmEnvironment(p, findings); err != nil {
+		return "", err
+	}
+	if err := pressAnyKey(p); err != nil {
+		return "", err
+	}
+	recover, err := choice(p, "Do you want to recover a wallet or create (scramble) a new one?", "Recover", "Create", "R", "C")
+	if err != nil {
+		return "", err
+	}
+	printStyled("\n")
+	slip39, err := choice(p, "Which wordlist are your backup words from?", "SLIP39", "BIP39", "S", "B")
+	if err != nil {
+		return "", err
+	}
+	mode := scrambler.ModeBIP39
+	if slip39 {
+		printStyled("\n")
+		share, err := choice(p, "Are your words a SLIP39 share? Its checksum will be kept valid.", "Share", "Other words", "S", "O")
+		if err != nil {
+			return "", err
+		}
+		mode = scrambler.ModeWords
+		if share {
+			mode = scrambler.ModeSLIP39
+		}
+	}
+	profile, err := chooseProfile(p, recover)
+	if err != nil {
+		return "", err
+	}
+	label, err := askLabel(p, recover)
+	if err != nil {
+		return "", err
+	}
+	// The key is derived while words are typed, so progress is only shown
+	// once there is nothing left to type.
+	var showProgress atomic.Bool
+	report := progressReporter(os.Stdout, "auto")
+	progress := func(pr scrambler.Progress) {
+		if report != nil && showProgress.Load() {
+			report(pr)
+		}
+	}
+	s := scrambler.New(scrambler.WithMode(mode), scrambler.WithProfile(profile), scrambler.WithLabel(label),
+		scrambler.WithProgress(progress))
+	words := s.Wordlist()
+	if p.fd >= 0 {
+		printStyled("\n")
+		p.hideWords, err = choice(p, "Do you want to hide the wallet and salt words while you type them?", "Hide", "Show", "H", "S")
+		if err != nil {
+			return "", err
+		}
+	}
+	if recover {
+		printStyled("\nLets recover your wallet\n")
+	} else {
+		printStyled("\nLets create a new wallet\n\nChoose a strong password (and be sure to remember it)\n")
+	}
+
+	var password1, password2 []byte
+	for {
+		printStyled("\n{cyan}Enter password: ")
+		if password1, err = p.secret(); err != nil {
+			return "", err
+		}
+
+		printStyled("{cyan}Confirm the password: ")
+		if password2, err = p.secret(); err != nil {
+			return "", err
+		}
+
+		if !bytes.Equal(password1, password2) {
+			printStyled("{red}{bold}\nError: Passwords do not match. Try again.")
+			continue
+		}
+
+		if !recover && isWeakPassword(password1) {
+			printStyled("\n{yellow}Warning: Your password is weak. It should be at least 8 characters long\n")
+			printStyled("{yellow}and include a mix of uppercase, lowercase, numbers, and special characters.\n")
+			printStyled("{yellow}Type 'yes' if you want to continue with this password :")
+			confirmation, err := p.line()
+			if err != nil {
+				return "", err
+			}
+			confirmation = strings.ToLower(confirmation)
+			if confirmation == "yes" {
+				printStyled("\n{green}Ok, weak password accepted.")
+				break
+			} else {
+				printStyled("\n{green}Please enter a stronger password.")
+				continue
+			}
+		} else {
+			printStyled("\n{green}Password accepted.")
+			break
+		}
+	}
+	if !recover {
+		printStyled("\n\n{yellow}Don't forget your password - there is {underline}NO WAY{reset}{yellow} to recover it!\n\n")
+	}
+
+	printStyled("\n")
+	var duress bool
+	if recover {
+		duress, err = choice(p, "Is your backup a two-slot duress backup?", "Duress", "Normal", "D", "N")
+	} else {
+		duress, err = choice(p, "Do you want a two-slot duress backup that a second password opens to a decoy wallet?", "Duress", "Normal", "D", "N")
+	}
+	if err != nil {
+		return "", err
+	}
+	var decoyPassword []byte
+	if duress && !recover {
+		if decoyPassword, err = readDuressPassword(p, password1); err != nil {
+			return "", err
+		}
+	}
+	if err := pressAnyKey(p); err != nil {
+		return "", err
+	}
+	var saltCount int
+	for {
+		if recover {
+			printStyled("\n{cyan}How many words in your salt? (0-16): ")
+		} else {
+			printStyled("\n{cyan}Enter the number of salt words (0-16, at least 4 recommended): ")
+		}
+
+		input, err := p.line()
+		if err != nil {
+			return "", err
+		}
+		saltCount, err = strconv.Atoi(input)
+		if err != nil || saltCount < 0 || saltCount > scrambler.MaxSaltWords {
+			printStyled("\n{red}Invalid input. Please enter a number between 0 and 16.")
+			continue
+		}
+		break
+	}
+
+	var saltWords []string
+
+	if recover {
+		printStyled("\n")
+		if saltCount > 0 {
+			printStyled(wordEntryHint)
+		}
+		for i := 0; i < saltCount; i++ {
+			for {
+				fmt.Printf("Enter salt word %d: ", i+1)
+				word, hint, err := p.word(words)
+				if err != nil {
+					return "", err
+				}
+				if word != "" {
+					saltWords = append(saltWords, word)
+					break
+				}
+				fmt.Println("Invalid word. The word must exist in the wordlist.")
+				if hint != "" {
+					fmt.Println(hint)
+				}
+			}
+		}
+		printStyled("\n{green}Salt words entered.")
+	} else {
+		if saltWords, err = generateSalt(p, words, saltCount); err != nil {
+			return "", err
+		}
+	}
+
+	printStyled("\n\n{cyan}Calculating key from your salt and password in the background.\n")
+	printStyled("{cyan}For security reasons, this is SUPPOSED to take a while, so enter your words meanwhile.\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	passwords := [][]byte{password1}
+	if len(decoyPassword) > 0 {
+		passwords = append(passwords, decoyPassword)
+	}
+	pending := deriveKeysInBackground(ctx, s, saltWords, passwords)
+
+	var walletWordCount int
+	for {
+		printStyled("\n{cyan}Enter the number of words in your wallet (" + wordCountHint(mode) + "): ")
+		input, err := p.line()
+		if err != nil {
+			return "", err
+		}
+		walletWordCount, err = strconv.Atoi(input)
+		if err == nil && mode.CheckWordCount(walletWordCount) == nil {
+			break
+		}
+		fmt.Println("Invalid input. Please enter " + wordCountHint(mode) + ".")
+	}
+
+	checkCount, err := askCheckCount(p, recover, duress)
+	if err != nil {
+		return "", err
+	}
+	printStyled("\n" + wordEntryHint)
+
+	var walletWords, decoyWords, newWords, checkWords []string
+	var backup *scrambler.DuressBackup
+	defer func() {
+		clearWords(walletWords)
+		clearWords(decoyWords)
+		if backup != nil {
+			clearWords(backup.Slots[0])
+			clearWords(backup.Slots[1])
+		}
+	}()
+	if duress && recover {
+		backup = &scrambler.DuressBackup{}
+		for i := range backup.Slots {
+			slot := fmt.Sprintf("slot %d ", i+1)
+			if backup.Slots[i], err = readValidWords(p, s, walletWordCount, slot+"word"); err != nil {
+				return "", err
+			}
+			if backup.Check[i], err = readWords(p, words, checkCount, slot+"check word"); err != nil {
+				return "", err
+			}
+		}
+	} else {
+		if walletWords, err = readValidWords(p, s, walletWordCount, "word"); err != nil {
+			return "", err
+		}
+		if duress && len(decoyPassword) > 0 {
+			printStyled("\n{cyan}Now enter the decoy wallet the duress password will open.\n")
+			if decoyWords, err = readValidWords(p, s, walletWordCount, "decoy word"); err != nil {
+				return "", err
+			}
+		}
+		if recover {
+			if checkWords, err = readWords(p, words, checkCount, "check word"); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var result keyResult
+	select {
+	case result = <-pending:
+	default:
+		printStyled("\n{cyan}Waiting for the key derivation to finish...\n")
+		showProgress.Store(true)
+		result = <-pending
+	}
+	if result.err != nil {
+		return "", result.err
+	}
+	key := result.keys[0]
+	defer key.Wipe()
+	var decoy *scrambler.Key
+	if len(result.keys) > 1 {
+		decoy = result.keys[1]
+		defer decoy.Wipe()
+	}
+	printStyled("\n{green}Key generated.\n")
+
+	switch {
+	case duress && recover:
+		newWords, err = key.OpenDuress(backup)
+	case duress:
+		backup, err = scrambler.SealDuress(key, walletWords, decoy, decoyWords, checkCount)
+	case recover:
+		if checkCount > 0 {
+			if err := key.VerifyCheckWords(walletWords, checkWords); err != nil {
+				return "", err
+			}
+			printStyled("\n{green}Check words match.\n")
+		}
+		newWords, err = key.Unscramble(walletWords)
+	default:
+		newWords, err = key.Scramble(walletWords)
+		if err == nil && checkCount > 0 {
+			checkWords, err = key.CheckWords(newWords, checkCount)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	show := func() {
+		if !recover {
+			printStyled("\n{bold}{underline}{cyan}Here are your new wallet words\n")
+			if len(saltWords) > 0 {
+				printBeautifully("Salt:", saltWords)
+			}
+		} else {
+			printStyled("\n{bold}{underline}{cyan}Here are your recovered wallet words\n")
+		}
+
+		if duress && !recover {
+			for i := range backup.Slots {
+				printBeautifully(fmt.Sprintf("Slot %d Words:", i+1), backup.Slots[i])
+				printBeautifully(fmt.Sprintf("Slot %d Check Words:", i+1), backup.Check[i])
+			}
+		} else {
+			printBeautifully("Wallet Words:", newWords)
+		}
+		if !recover && len(checkWords) > 0 {
+			printBeautifully("Check Words:", checkWords)
+		}
+
+		if !recover {
+			printStyled("\nKDF profile: {bold}" + key.Profile().ID + "\n")
+			if label != "" {
+				printStyled("Wallet label: {bold}" + label + "\n")
+			}
+			record := []string{"the salt", "words"}
+			if duress {
+				record[1] = "both slots with their check words"
+			} else if checkCount > 0 {
+				record = append(record, "check words")
+			}
+			if label != "" {
+				record = append(record, "wallet label")
+			}
+			record = append(record, "KDF profile")
+			printStyled("\n\nWrite " + strings.Join(record[:len(record)-1], ", ") + " and " + record[len(record)-1] + " down and store them in a safe place.\n")
+			if duress {
+				printStyled("Keep each slot together with its check words.\n")
+			}
+			printStyled("\n")
+		}
+	}
+	p.wipe()
+	note := ""
+	if !recover {
+		note = quizNote
+	}
+	if err := displaySecrets(p, show, note); err != nil {
+		return "", err
+	}
+
+	if !recover {
+		written := transcript{salt: saltWords}
+		if duress {
+			for i := range backup.Slots {
+				written.slots = append(written.slots, backup.Slots[i])
+				written.check = append(written.check, backup.Check[i])
+			}
+		} else {
+			written.slots = [][]string{newWords}
+			written.check = [][]string{checkWords}
+		}
+		roundTrip := func(t transcript) error {
+			showProgress.Store(true)
+			keys, err := rederiveKeys(ctx, p, s, t.salt, decoy != nil)
+			if err != nil {
+				return err
+			}
+			defer wipeKeys(keys)
+			if duress {
+				b := &scrambler.DuressBackup{Slots: [2][]string{t.slots[0], t.slots[1]}, Check: [2][]string{t.check[0], t.check[1]}}
+				if err := openMatches(keys[0], b, walletWords); err != nil || decoy == nil {
+					return err
+				}
+				return openMatches(keys[1], b, decoyWords)
+			}
+			if len(t.check[0]) > 0 {
+				if err := keys[0].VerifyCheckWords(t.slots[0], t.check[0]); err != nil {
+					return err
+				}
+			}
+			words, err := keys[0].Unscramble(t.slots[0])
+			defer clearWords(words)
+			if err != nil {
+				return err
+			}
+			if !sameWords(words, walletWords) {
+				return errRoundTrip
+			}
+			return nil
+		}
+		display := func() error { return displaySecrets(p, show, "") }
+		if err := verifyTranscript(p, words, written, display, roundTrip); err != nil {
+			return "", err
+		}
+	}
+	clearWords(newWords)
+	key.Wipe()
+	if decoy != nil {
+		decoy.Wipe()
+	}
+
+	if recover {
+		return fmt.Sprintf("Recovered a %d-word wallet. The words are no longer shown and the screen has been cleared.", walletWordCount), nil
+	}
+	summary = fmt.Sprintf("Scrambled a %d-word wallet with KDF profile %s", walletWordCount, key.Profile().ID)
+	if label != "" {
+		summary += " and wallet label " + label
+	}
+	return summary + ". Your written copy has been verified and the screen has been cleared.", nil
+}
+
+// keyResult is the outcome of deriveKeysInBackground.
+type keyResult struct {
+	keys []*scrambler.Key
+	err  error
+}
+
+// deriveKeysInBackground derives a key for each password in a goroutine, one
+// after the other so only one Argon2id instance holds memory at a time. The
+// passwords are copied, so the caller may wipe them at any time. When ctx is
+// cancelled the derivation stops as soon as it can and any keys that were
+// not received are wiped.
+func deriveKeysInBackground(ctx context.Context, s *scrambler.Scrambler, salt []string, passwords [][]byte) <-chan keyResult {
+	copies := make([]*secmem.Buffer, len(passwords))
+	for i, password := range passwords {
+		copies[i] = secmem.New(len(password))
+		copies[i].Write(password)
+	}
+	results := make(chan keyResult)
+	go func() {
+		var result keyResult
+		for _, password := range copies {
+			key, err := s.DeriveKeyContext(ctx, password.Bytes(), salt)
+			if err != nil {
+				result.err = err
+				break
+			}
+			result.keys = append(result.keys, key)
+		}
+		for _, password := range copies {
+			password.Destroy()
+		}
+		if result.err != nil {
+			wipeKeys(result.keys)
+			result.keys = nil
+		}
+		select {
+		case results <- result:
+		case <-ctx.Done():
+			wipeKeys(result.keys)
+		}
+	}()
+	return results
+}
+
+// rederiveKeys asks for the password, and the duress password when decoy is
+// set, and derives their keys again from salt as typed from the written
+// copy, so the transcription check does not rely on anything kept in memory.
+func rederiveKeys(ctx context.Context, p *prompter, s *scrambler.Scrambler, salt []string, decoy bool) ([]*scrambler.Key, error) {
+	printStyled("\n{cyan}Enter your password: ")
+	password, err := p.secret()
+	if err != nil {
+		return nil, err
+	}
+	passwords := [][]byte{password}
+	if decoy {
+		printStyled("{cyan}Enter your duress password: ")
+		decoyPassword, err := p.secret()
+		if err != nil {
+			return nil, err
+		}
+		passwords = append(passwords, decoyPassword)
+	}
+	printStyled("\n{cyan}Deriving the key again from the salt you entered, this takes as long as before...\n")
+	result := <-deriveKeysInBackground(ctx, s, salt, passwords)
+	p.wipe()
+	return result.keys, result.err
+}
+
+func wipeKeys(keys []*scrambler.Key) {
+	for _, key := range keys {
+		key.Wipe()
+	}
+}
+
+// askLabel asks for the wallet label that separates the keys of wallets
+// scrambled with the same password and salt.
+func askLabel(p *prompter, recover bool) (string, error) {
+	if recover {
+		printStyled("\n{cyan}Enter the wallet label you scrambled with (press Enter if none): ")
+		return p.line()
+	}
+	printStyled("\nA wallet label, such as a name or a number, gives this wallet its own key,\n")
+	printStyled("so other wallets scrambled with the same password and salt stay independent.\n")
+	printStyled("{cyan}Enter a wallet label (press Enter for none): ")
+	label, err := p.line()
+	if err == nil && label == "" {
+		printStyled("{yellow}Warning: without a label, every wallet scrambled with this password and salt\n")
+		printStyled("{yellow}uses the same key, and comparing their scrambled words leaks information.\n")
+	}
+	return label, err
+}
+
+// readDuressPassword asks for the duress password of a new duress backup. An
+// empty password fills the second slot with random words instead of a decoy.
+func readDuressPassword(p *prompter, password []byte) ([]byte, error) {
+	printStyled("\nThe duress password opens the backup to a decoy wallet of your choice.\n")
+	printStyled("Press Enter without a password to fill the second slot with random words instead.\n")
+	for {
+		printStyled("\n{cyan}Enter duress password: ")
+		decoyPassword, err := p.secret()
+		if err != nil {
+			return nil, err
+		}
+		if len(decoyPassword) == 0 {
+			printStyled("\n{green}The second slot will hold random words.\n")
+			return nil, nil
+		}
+		printStyled("{cyan}Confirm the duress password: ")
+		confirmation, err := p.secret()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !bytes.Equal(decoyPassword, confirmation):
+			printStyled("{red}{bold}\nError: Passwords do not match. Try again.")
+		case bytes.Equal(decoyPassword, password):
+			printStyled("{red}{bold}\nError: The duress password must differ from your password. Try again.")
+		default:
+			printStyled("\n{green}Duress password accepted.\n")
+			return decoyPassword, nil
+		}
+	}
+}
+
+// askCheckCount asks how many check words to emit, or how many were written
+// down when recovering. A duress backup needs check words to find its slot.
+func askCheckCount(p *prompter, recover, duress bool) (int, error) {
+	min := 0
+	if duress {
+		min = scrambler.MinDuressCheckWords
+	}
+	for {
+		switch {
+		case recover && duress:
+			printStyled(fmt.Sprintf("\n{cyan}How many check words per slot did you write down? (%d-%d): ", min, scrambler.MaxCheckWords))
+		case recover:
+			printStyled(fmt.Sprintf("\n{cyan}How many check words did you write down? (0-%d): ", scrambler.MaxCheckWords))
+		case duress:
+			printStyled(fmt.Sprintf("\n{cyan}How many check words per slot? (%d-%d): ", min, scrambler.MaxCheckWords))
+		default:
+			printStyled(fmt.Sprintf("\n{cyan}How many check words to detect a wrong password on recovery? (0-%d, 0 for none): ", scrambler.MaxCheckWords))
+		}
+		input, err := p.line()
+		if err != nil {
+			return 0, err
+		}
+		count, err := strconv.Atoi(input)
+		if err == nil && count >= min && count <= scrambler.MaxCheckWords {
+			return count, nil
+		}
+		fmt.Printf("Invalid input. Please enter a number between %d and %d.\n", min, scrambler.MaxCheckWords)
+	}
+}
+
+// wordEntryHint is shown before words are typed.
+const wordEntryHint = "{cyan}The first 4 letters of a word are enough; Tab completes a word.\n"
+
+// readWords prompts for n words from list, naming each "<kind> <position>".
+func readWords(p *prompter, list scrambler.Wordlist, n int, kind string) ([]string, error) {
+	words := make([]string, n)
+	for i := range words {
+		for {
+			fmt.Printf("Enter %s %d: ", kind, i+1)
+			word, hint, err := p.word(list)
+			if err != nil {
+				clearWords(words)
+				return nil, err
+			}
+			if word != "" {
+				words[i] = word
+				break
+			}
+			printStyled("\n{red}Invalid word. Please enter a valid word from the wordlist.\n")
+			if hint != "" {
+				printStyled("{yellow}" + hint + "\n")
+			}
+		}
+	}
+	return words, nil
+}
+
+// readValidWords reads n wallet words with readWords until they pass the
+// checksum or padding check of the scrambler's mode. A rejected list is
+// wiped and asked for again, while the key derivation keeps running.
+func readValidWords(p *prompter, s *scrambler.Scrambler, n int, kind string) ([]string, error) {
+	for {
+		words, err := readWords(p, s.Wordlist(), n, kind)
+		if err != nil {
+			return nil, err
+		}
+		err = s.ValidateWords(words)
+		if err == nil {
+			return words, nil
+		}
+		clearWords(words)
+		printStyled("\n{red}These words are not valid: " + err.Error() + ".\n")
+		printStyled("{yellow}A word was probably mistyped. Please enter all " + strconv.Itoa(n) + " words again.\n\n")
+	}
+}
+
+// chooseProfile asks for the KDF profile. New wallets use the default profile
+// unless more than one is registered. When recovering, an empty answer
+// selects the default, which every backup made before profiles existed uses.
+// A profile the machine does not have the memory for is never returned.
+func chooseProfile(p *prompter, recover bool) (scrambler.KDFProfile, error) {
+	profiles := scrambler.Profiles()
+	if !recover && len(profiles) == 1 {
+		if err := checkMemory(profiles[0]); err != nil {
+			return scrambler.KDFProfile{}, err
+		}
+		return profiles[0], nil
+	}
+	printStyled("\n{bold}{cyan}Available KDF profiles:\n")
+	for _, profile := range profiles {
+		printStyled("  " + describeProfile(profile) + "\n")
+	}
+	for {
+		printStyled("{cyan}Enter the KDF profile (press Enter for " + scrambler.DefaultProfile + "): ")
+		id, err := p.line()
+		if err != nil {
+			return scrambler.KDFProfile{}, err
+		}
+		if id == "" {
+			id = scrambler.DefaultProfile
+		}
+		profile, ok := scrambler.LookupProfile(id)
+		if !ok {
+			printStyled("{red}Unknown KDF profile.\n")
+			continue
+		}
+		if err := checkMemory(profile); err != nil {
+			printStyled("{red}" + err.Error() + "\n")
+			continue
+		}
+		return profile, nil
+	}
+}
+
+// wordCountHint describes the valid numbers of wallet words in mode.
+func wordCountHint(mode scrambler.Mode) string {
+	counts := mode.WordCounts()
+	first, last := counts[0], counts[len(counts)-1]
+	if last-first+1 == len(counts) {
+		return fmt.Sprintf("a number between %d and %d", first, last)
+	}
+	hint := make([]string, len(counts)-1)
+	for i, n := range counts[:len(counts)-1] {
+		hint[i] = strconv.Itoa(n)
+	}
+	return strings.Join(hint, ", ") + " or " + strconv.Itoa(last)
+}
@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package secmem
+
+func alloc(size int) ([]byte, bool) {
+	return make([]byte, size), false
+}
+
+func free(mem []byte, locked bool) {}
+
+func protect() error { return nil }
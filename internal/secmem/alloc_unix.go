@@ -0,0 +1,30 @@
+//go:build unix
+
+package secmem
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// alloc maps anonymous pages of its own for every buffer, so unlocking one
+// buffer never unlocks a page another buffer still relies on.
+func alloc(size int) ([]byte, bool) {
+	pageSize := os.Getpagesize()
+	size = (size + pageSize - 1) / pageSize * pageSize
+	mem, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return make([]byte, size), false
+	}
+	excludeFromDump(mem)
+	return mem, unix.Mlock(mem) == nil
+}
+
+func free(mem []byte, locked bool) {
+	mem = mem[:cap(mem)]
+	if locked {
+		unix.Munlock(mem)
+	}
+	unix.Munmap(mem)
+}
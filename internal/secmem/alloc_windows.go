@@ -0,0 +1,33 @@
+package secmem
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// alloc takes page-aligned memory from the Go heap, which is never moved,
+// and locks it into the working set.
+func alloc(size int) ([]byte, bool) {
+	pageSize := os.Getpagesize()
+	size = (size + pageSize - 1) / pageSize * pageSize
+	raw := make([]byte, size+pageSize)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&raw[0])) % uintptr(pageSize)); rem != 0 {
+		offset = pageSize - rem
+	}
+	mem := raw[offset : offset+size : offset+size]
+	err := windows.VirtualLock(uintptr(unsafe.Pointer(&mem[0])), uintptr(len(mem)))
+	return mem, err == nil
+}
+
+func free(mem []byte, locked bool) {
+	mem = mem[:cap(mem)]
+	if locked {
+		windows.VirtualUnlock(uintptr(unsafe.Pointer(&mem[0])), uintptr(len(mem)))
+	}
+}
+
+// Windows does not write core dumps of its own accord.
+func protect() error { return nil }
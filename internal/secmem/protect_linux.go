@@ -0,0 +1,14 @@
+package secmem
+
+import "golang.org/x/sys/unix"
+
+func protect() error {
+	if err := unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{}); err != nil {
+		return err
+	}
+	return unix.Prctl(unix.PR_SET_DUMPABLE, 0, 0, 0, 0)
+}
+
+func excludeFromDump(mem []byte) {
+	unix.Madvise(mem, unix.MADV_DONTDUMP)
+}
@@ -0,0 +1,11 @@
+//go:build unix && !linux
+
+package secmem
+
+import "golang.org/x/sys/unix"
+
+func protect() error {
+	return unix.Setrlimit(unix.RLIMIT_CORE, &unix.Rlimit{})
+}
+
+func excludeFromDump(mem []byte) {}
@@ -0,0 +1,183 @@
+// Package secmem keeps secrets in memory that is locked against swapping,
+// excluded from core dumps where the platform allows it, and wiped when no
+// longer needed.
+package secmem
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrFull is returned when a Buffer has no room left.
+var ErrFull = errors.New("secure buffer is full")
+
+var (
+	liveMu sync.Mutex
+	live   = map[*Buffer]struct{}{}
+)
+
+// Buffer is a fixed-capacity byte buffer for secrets. Its memory is
+// allocated outside the Go heap where possible, so it is never copied by the
+// runtime, and locked into RAM.
+type Buffer struct {
+	mem    []byte
+	n      int
+	locked bool
+}
+
+// New allocates a buffer that can hold capacity bytes. If the memory cannot
+// be locked the buffer is still usable; Locked reports whether it is.
+func New(capacity int) *Buffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	mem, locked := alloc(capacity)
+	b := &Buffer{mem: mem[:capacity], locked: locked}
+	liveMu.Lock()
+	live[b] = struct{}{}
+	liveMu.Unlock()
+	return b
+}
+
+// Bytes returns the contents of the buffer. The slice aliases the buffer and
+// becomes invalid after Destroy.
+func (b *Buffer) Bytes() []byte {
+	return b.mem[:b.n]
+}
+
+// Len returns the number of bytes held.
+func (b *Buffer) Len() int {
+	return b.n
+}
+
+// Locked reports whether the buffer memory is locked against swapping.
+func (b *Buffer) Locked() bool {
+	return b.locked
+}
+
+// AppendByte adds c to the end of the buffer.
+func (b *Buffer) AppendByte(c byte) error {
+	if b.n == len(b.mem) {
+		return ErrFull
+	}
+	b.mem[b.n] = c
+	b.n++
+	return nil
+}
+
+// Write appends p to the buffer. It writes nothing and returns ErrFull if p
+// does not fit.
+func (b *Buffer) Write(p []byte) (int, error) {
+	if len(p) > len(b.mem)-b.n {
+		return 0, ErrFull
+	}
+	b.n += copy(b.mem[b.n:], p)
+	return len(p), nil
+}
+
+// ReadFrom appends everything read from r until end of file. It returns
+// ErrFull only if r still has data once the buffer is full, so input that
+// fills the buffer exactly is accepted.
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		if b.n == len(b.mem) {
+			return total, b.probe(r)
+		}
+		n, err := r.Read(b.mem[b.n:])
+		b.n += n
+		total += int64(n)
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// probe reads one byte from r into a spare byte to tell whether r is at end
+// of file, and wipes it again.
+func (b *Buffer) probe(r io.Reader) error {
+	var spare [1]byte
+	defer wipe(spare[:])
+	for {
+		n, err := r.Read(spare[:])
+		if n > 0 {
+			return ErrFull
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Truncate discards all but the first n bytes, wiping the rest.
+func (b *Buffer) Truncate(n int) {
+	if n < 0 || n > b.n {
+		return
+	}
+	wipe(b.mem[n:b.n])
+	b.n = n
+}
+
+// Wipe zeroes the contents of the buffer and empties it.
+func (b *Buffer) Wipe() {
+	wipe(b.mem)
+	b.n = 0
+}
+
+// Destroy wipes the buffer and releases its memory. It is safe to call more
+// than once.
+func (b *Buffer) Destroy() {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	b.destroy()
+}
+
+func (b *Buffer) destroy() {
+	if b.mem == nil {
+		return
+	}
+	wipe(b.mem)
+	free(b.mem, b.locked)
+	b.mem, b.n = nil, 0
+	delete(live, b)
+}
+
+// WipeAll zeroes every buffer that is still alive without releasing its
+// memory, so code still holding a buffer does not crash. It is meant for
+// signal handlers and other abrupt exits.
+func WipeAll() {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	for b := range live {
+		b.Wipe()
+	}
+}
+
+// Protect hardens the process against leaking secrets: core dumps are
+// disabled and, on Linux, the process is marked non-dumpable so it cannot be
+// attached to or read through /proc by other processes of the same user. It
+// also reports whether memory can be locked at all.
+func Protect() error {
+	if err := protect(); err != nil {
+		return err
+	}
+	probe := New(1)
+	defer probe.Destroy()
+	if !probe.locked {
+		return errors.New("memory cannot be locked, secrets may be swapped to disk")
+	}
+	return nil
+}
+
+func wipe(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
@@ -0,0 +1,110 @@
+package secmem
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadFrom(t *testing.T) {
+	failure := errors.New("device removed")
+	for _, tt := range []struct {
+		name     string
+		capacity int
+		r        io.Reader
+		want     string
+		err      error
+	}{
+		{"short", 8, strings.NewReader("abc"), "abc", nil},
+		{"empty", 8, strings.NewReader(""), "", nil},
+		{"exact capacity", 4, strings.NewReader("abcd"), "abcd", nil},
+		{"exact capacity one byte at a time", 4, iotest.OneByteReader(strings.NewReader("abcd")), "abcd", nil},
+		{"exact capacity with end of file", 4, iotest.DataErrReader(strings.NewReader("abcd")), "abcd", nil},
+		{"over capacity", 4, strings.NewReader("abcde"), "abcd", ErrFull},
+		{"over capacity one byte at a time", 4, iotest.OneByteReader(strings.NewReader("abcde")), "abcd", ErrFull},
+		{"error", 8, iotest.ErrReader(failure), "", failure},
+		{"error after data", 4, io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(failure)), "ab", failure},
+		{"error when full", 2, iotest.TimeoutReader(strings.NewReader("ab")), "ab", iotest.ErrTimeout},
+	} {
+		b := New(tt.capacity)
+		n, err := b.ReadFrom(tt.r)
+		if !errors.Is(err, tt.err) || string(b.Bytes()) != tt.want || n != int64(len(tt.want)) {
+			t.Errorf("%s: ReadFrom = %d, %v with %q, want %d, %v with %q", tt.name, n, err, b.Bytes(), len(tt.want), tt.err, tt.want)
+		}
+		b.Destroy()
+	}
+}
+
+func TestAppend(t *testing.T) {
+	b := New(4)
+	defer b.Destroy()
+	if err := b.AppendByte('a'); err != nil {
+		t.Fatalf("AppendByte: %v", err)
+	}
+	if n, err := b.Write([]byte("bcd")); n != 3 || err != nil {
+		t.Fatalf("Write = %d, %v, want 3, nil", n, err)
+	}
+	if err := b.AppendByte('e'); !errors.Is(err, ErrFull) {
+		t.Errorf("AppendByte on a full buffer = %v, want ErrFull", err)
+	}
+	if n, err := b.Write([]byte("e")); n != 0 || !errors.Is(err, ErrFull) {
+		t.Errorf("Write on a full buffer = %d, %v, want 0, ErrFull", n, err)
+	}
+	if got := string(b.Bytes()); got != "abcd" || b.Len() != 4 {
+		t.Errorf("Bytes = %q, Len = %d, want \"abcd\", 4", got, b.Len())
+	}
+
+	b.Truncate(1)
+	if n, err := b.Write([]byte("xyzw")); n != 0 || !errors.Is(err, ErrFull) {
+		t.Errorf("Write that does not fit = %d, %v, want 0, ErrFull", n, err)
+	}
+	if got := string(b.Bytes()); got != "a" {
+		t.Errorf("Bytes after a rejected Write = %q, want \"a\"", got)
+	}
+	if rest := b.mem[1:4]; !bytes.Equal(rest, make([]byte, 3)) {
+		t.Errorf("Truncate left %q behind", rest)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	// A buffer on the Go heap stays readable after Destroy, unlike mapped
+	// pages, so the wipe can be observed.
+	mem := make([]byte, 8)
+	b := &Buffer{mem: mem}
+	liveMu.Lock()
+	live[b] = struct{}{}
+	liveMu.Unlock()
+	b.Write([]byte("secret"))
+
+	b.Destroy()
+	if !bytes.Equal(mem, make([]byte, len(mem))) {
+		t.Errorf("Destroy left %q in memory", mem)
+	}
+	if b.Len() != 0 || len(b.Bytes()) != 0 {
+		t.Errorf("Destroy left Len = %d", b.Len())
+	}
+	liveMu.Lock()
+	_, alive := live[b]
+	liveMu.Unlock()
+	if alive {
+		t.Error("Destroy left the buffer in the live set")
+	}
+	b.Destroy()
+	if err := b.AppendByte('x'); !errors.Is(err, ErrFull) {
+		t.Errorf("AppendByte after Destroy = %v, want ErrFull", err)
+	}
+}
+
+func TestWipeAll(t *testing.T) {
+	b := New(8)
+	defer b.Destroy()
+	b.Write([]byte("secret"))
+	held := b.Bytes()
+	WipeAll()
+	if b.Len() != 0 || !bytes.Equal(held, make([]byte, len(held))) {
+		t.Errorf("WipeAll left %q, Len = %d", held, b.Len())
+	}
+}
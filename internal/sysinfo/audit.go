@@ -0,0 +1,46 @@
+package sysinfo
+
+// Status is the outcome of an audit check.
+type Status int
+
+const (
+	Pass Status = iota // nothing found that puts secrets at risk
+	Warn               // a risk the user should know about
+	Fail               // the machine is not air-gapped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Warn:
+		return "WARN"
+	}
+	return "FAIL"
+}
+
+// Finding is the result of one audit check.
+type Finding struct {
+	Check  string
+	Status Status
+	Detail string
+}
+
+// Audit inspects the machine for conditions that put secrets entered on it
+// at risk: network interfaces that are up, default routes, swap that is not
+// encrypted, a virtual machine or container around the process, and whether
+// it runs from a live system such as Tails, which is only reported. Where the platform cannot be
+// inspected a single warning says so.
+func Audit() []Finding {
+	return audit()
+}
+
+// Worst returns the most severe status of findings, or Pass when there are
+// none.
+func Worst(findings []Finding) Status {
+	worst := Pass
+	for _, f := range findings {
+		worst = max(worst, f.Status)
+	}
+	return worst
+}
@@ -0,0 +1,336 @@
+package sysinfo
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// rtfUp is the RTF_UP flag of a route in /proc/net/route and
+// /proc/net/ipv6_route.
+const rtfUp = 0x1
+
+// iffUp is the IFF_UP flag of an interface in /sys/class/net/*/flags.
+const iffUp = 0x1
+
+// hypervisorVendors are DMI vendor and product names of virtual machines.
+var hypervisorVendors = []string{"QEMU", "KVM", "VMware", "VirtualBox", "innotek", "Xen", "Microsoft Corporation", "Parallels", "Bochs", "BHYVE"}
+
+func audit() []Finding {
+	return []Finding{
+		auditInterfaces(),
+		auditRoutes(),
+		auditSwap(),
+		auditVirtualization(),
+		auditLiveSystem(),
+	}
+}
+
+// auditInterfaces fails when a network interface other than loopback is up.
+func auditInterfaces() Finding {
+	f := Finding{Check: "Network interfaces"}
+	entries, err := os.ReadDir("/sys/class/net")
+	if err != nil {
+		f.Status, f.Detail = Warn, "cannot list network interfaces: "+err.Error()
+		return f
+	}
+	var up, down []string
+	for _, e := range entries {
+		name := e.Name()
+		if name == "lo" {
+			continue
+		}
+		if interfaceUp(name) {
+			up = append(up, name)
+		} else {
+			down = append(down, name)
+		}
+	}
+	switch {
+	case len(up) > 0:
+		f.Status, f.Detail = Fail, "up: "+strings.Join(up, ", ")
+	case len(down) > 0:
+		f.Detail = "all down: " + strings.Join(down, ", ")
+	default:
+		f.Detail = "none besides loopback"
+	}
+	return f
+}
+
+// interfaceUp reports whether the interface is administratively up and not
+// known to be without a link.
+func interfaceUp(name string) bool {
+	dir := filepath.Join("/sys/class/net", name)
+	flags, _ := readFile(filepath.Join(dir, "flags"))
+	state, _ := readFile(filepath.Join(dir, "operstate"))
+	return interfaceState(flags, state)
+}
+
+// interfaceState reports whether an interface with the given contents of
+// its flags and operstate files is up. Unreadable values count as up.
+func interfaceState(flags, operstate string) bool {
+	value, err := strconv.ParseUint(strings.TrimPrefix(flags, "0x"), 16, 64)
+	if err == nil && value&iffUp == 0 {
+		return false
+	}
+	switch operstate {
+	case "down", "lowerlayerdown", "notpresent", "dormant":
+		return false
+	}
+	return true
+}
+
+// auditRoutes fails when an IPv4 or IPv6 default route exists.
+func auditRoutes() Finding {
+	f := Finding{Check: "Default route"}
+	var via []string
+	readable := false
+	if routes, err := parseFile("/proc/net/route", defaultRoutes); err == nil {
+		readable = true
+		via = append(via, routes...)
+	}
+	if routes, err := parseFile("/proc/net/ipv6_route", defaultRoutes6); err == nil {
+		readable = true
+		for _, name := range routes {
+			via = append(via, name+" (IPv6)")
+		}
+	}
+	switch {
+	case !readable:
+		f.Status, f.Detail = Warn, "cannot read the routing tables"
+	case len(via) > 0:
+		f.Status, f.Detail = Fail, "via "+strings.Join(via, ", ")
+	default:
+		f.Detail = "none"
+	}
+	return f
+}
+
+// defaultRoutes returns the interfaces of the default routes that are up in
+// a /proc/net/route table.
+func defaultRoutes(r io.Reader) ([]string, error) {
+	lines, err := scanLines(r)
+	var names []string
+	for _, line := range lines[min(1, len(lines)):] {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[1] != "00000000" {
+			continue
+		}
+		if flags, err := strconv.ParseUint(fields[3], 16, 64); err == nil && flags&rtfUp != 0 {
+			names = append(names, fields[0])
+		}
+	}
+	return names, err
+}
+
+// defaultRoutes6 returns the interfaces of the default routes that are up in
+// a /proc/net/ipv6_route table, leaving out the unreachable routes the kernel
+// keeps on loopback.
+func defaultRoutes6(r io.Reader) ([]string, error) {
+	lines, err := scanLines(r)
+	var names []string
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 10 || fields[1] != "00" || strings.Trim(fields[0], "0") != "" || fields[9] == "lo" {
+			continue
+		}
+		if flags, err := strconv.ParseUint(fields[8], 16, 64); err == nil && flags&rtfUp != 0 {
+			names = append(names, fields[9])
+		}
+	}
+	return names, err
+}
+
+// auditSwap warns about active swap that is neither compressed RAM nor an
+// encrypted device-mapper target. Secrets are kept in locked memory, but
+// anything else the program or the terminal holds could be written to it.
+func auditSwap() Finding {
+	f := Finding{Check: "Swap"}
+	devices, err := parseFile("/proc/swaps", swapDevices)
+	if err != nil {
+		f.Status, f.Detail = Warn, "cannot read /proc/swaps: "+err.Error()
+		return f
+	}
+	var plain, safe []string
+	for _, device := range devices {
+		if swapEncrypted(device) {
+			safe = append(safe, device)
+		} else {
+			plain = append(plain, device)
+		}
+	}
+	switch {
+	case len(plain) > 0:
+		f.Status, f.Detail = Warn, "not encrypted: "+strings.Join(plain, ", ")
+	case len(safe) > 0:
+		f.Detail = "encrypted or in RAM: " + strings.Join(safe, ", ")
+	default:
+		f.Detail = "none"
+	}
+	return f
+}
+
+// swapDevices returns the swap devices and files listed in /proc/swaps.
+func swapDevices(r io.Reader) ([]string, error) {
+	lines, err := scanLines(r)
+	var devices []string
+	for _, line := range lines[min(1, len(lines)):] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			devices = append(devices, fields[0])
+		}
+	}
+	return devices, err
+}
+
+// swapEncrypted reports whether a swap device is compressed RAM or a
+// dm-crypt mapping.
+func swapEncrypted(device string) bool {
+	if strings.HasPrefix(device, "/dev/zram") {
+		return true
+	}
+	resolved, err := filepath.EvalSymlinks(device)
+	if err != nil || !strings.HasPrefix(resolved, "/dev/dm-") {
+		return false
+	}
+	uuid, err := readFile(filepath.Join("/sys/block", filepath.Base(resolved), "dm/uuid"))
+	return err == nil && strings.HasPrefix(uuid, "CRYPT-")
+}
+
+// auditVirtualization warns when the process runs in a container or a
+// virtual machine, whose host can read its memory and screen.
+func auditVirtualization() Finding {
+	f := Finding{Check: "Virtualization"}
+	var found []string
+	if exists("/.dockerenv") || exists("/run/.containerenv") {
+		found = append(found, "container")
+	} else if inContainer, _ := parseFile("/proc/1/cgroup", containerCgroup); inContainer {
+		found = append(found, "container")
+	}
+	var dmi []string
+	for _, name := range []string{"sys_vendor", "product_name"} {
+		if value, err := readFile("/sys/class/dmi/id/" + name); err == nil {
+			dmi = append(dmi, value)
+		}
+	}
+	vm := hypervisorVendor(dmi)
+	if vm == "" {
+		if flagged, _ := parseFile("/proc/cpuinfo", hypervisorFlag); flagged {
+			vm = "hypervisor"
+		}
+	}
+	if vm != "" {
+		found = append(found, "virtual machine ("+vm+")")
+	}
+	if len(found) > 0 {
+		f.Status, f.Detail = Warn, "running in a "+strings.Join(found, " inside a ")
+	} else {
+		f.Detail = "bare metal"
+	}
+	return f
+}
+
+// containerCgroup reports whether a /proc/1/cgroup file names a container
+// runtime.
+func containerCgroup(r io.Reader) (bool, error) {
+	lines, err := scanLines(r)
+	for _, line := range lines {
+		for _, marker := range []string{"docker", "lxc", "kubepods", "containerd", "libpod"} {
+			if strings.Contains(line, marker) {
+				return true, err
+			}
+		}
+	}
+	return false, err
+}
+
+// hypervisorVendor returns the first of the DMI vendor and product names
+// that names a virtual machine, or "".
+func hypervisorVendor(names []string) string {
+	for _, name := range names {
+		for _, vendor := range hypervisorVendors {
+			if strings.Contains(name, vendor) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// hypervisorFlag reports whether /proc/cpuinfo lists the hypervisor flag
+// for the first CPU.
+func hypervisorFlag(r io.Reader) (bool, error) {
+	lines, err := scanLines(r)
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.TrimSpace(name) == "flags" {
+			for _, f := range strings.Fields(value) {
+				if f == "hypervisor" {
+					return true, err
+				}
+			}
+			return false, err
+		}
+	}
+	return false, err
+}
+
+// auditLiveSystem reports whether the system runs from a live medium, which
+// forgets everything written to disk when it shuts down. A freshly installed
+// dedicated machine is just as suitable, so this check only informs.
+func auditLiveSystem() Finding {
+	f := Finding{Check: "Live system"}
+	if tails, _ := parseFile("/etc/os-release", tailsRelease); tails {
+		f.Detail = "Tails"
+		return f
+	}
+	if exists("/run/live/medium") || exists("/lib/live/mount") || exists("/run/initramfs/live") {
+		f.Detail = "running from a live medium"
+		return f
+	}
+	f.Detail = "installed system; anything written to disk may persist"
+	return f
+}
+
+// tailsRelease reports whether an os-release file identifies Tails.
+func tailsRelease(r io.Reader) (bool, error) {
+	lines, err := scanLines(r)
+	for _, line := range lines {
+		if line == "ID=tails" || line == `ID="tails"` {
+			return true, err
+		}
+	}
+	return false, err
+}
+
+func readFile(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	return strings.TrimSpace(string(data)), err
+}
+
+// parseFile opens the file name and hands it to parse.
+func parseFile[T any](name string, parse func(io.Reader) (T, error)) (T, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer f.Close()
+	return parse(f)
+}
+
+func scanLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
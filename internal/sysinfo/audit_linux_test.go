@@ -0,0 +1,180 @@
+package sysinfo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterfaceState(t *testing.T) {
+	for _, tt := range []struct {
+		flags, operstate string
+		want             bool
+	}{
+		{"0x1003", "up", true},
+		{"0x1003", "unknown", true},
+		{"0x1002", "down", false}, // administratively down
+		{"0x1003", "down", false}, // no carrier
+		{"0x1003", "dormant", false},
+		{"0x1002", "up", false},
+		{"", "", true},
+	} {
+		if got := interfaceState(tt.flags, tt.operstate); got != tt.want {
+			t.Errorf("interfaceState(%q, %q) = %v, want %v", tt.flags, tt.operstate, got, tt.want)
+		}
+	}
+}
+
+const routeHeader = "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"
+
+func TestDefaultRoutes(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		table string
+		want  []string
+	}{
+		{"empty", routeHeader, nil},
+		{"default", routeHeader +
+			"eth0\t00000000\t0102A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n" +
+			"eth0\t0002A8C0\t00000000\t0001\t0\t0\t100\t00FFFFFF\t0\t0\t0\n", []string{"eth0"}},
+		{"subnet only", routeHeader +
+			"eth0\t0002A8C0\t00000000\t0001\t0\t0\t100\t00FFFFFF\t0\t0\t0\n", nil},
+		{"down", routeHeader +
+			"wlan0\t00000000\t0102A8C0\t0002\t0\t0\t600\t00000000\t0\t0\t0\n", nil},
+		{"two", routeHeader +
+			"eth0\t00000000\t0102A8C0\t0003\t0\t0\t100\t00000000\t0\t0\t0\n" +
+			"wlan0\t00000000\t0102A8C0\t0003\t0\t0\t600\t00000000\t0\t0\t0\n", []string{"eth0", "wlan0"}},
+		{"malformed", routeHeader + "eth0 00000000\n", nil},
+	} {
+		got, err := defaultRoutes(strings.NewReader(tt.table))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: defaultRoutes = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultRoutes6(t *testing.T) {
+	const (
+		zero     = "00000000000000000000000000000000"
+		gateway  = "fe800000000000000000000000000001"
+		prefix   = "20010db8000000000000000000000000"
+		loopback = "00000000000000000000000000000001"
+	)
+	for _, tt := range []struct {
+		name  string
+		table string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"default", zero + " 00 " + zero + " 00 " + gateway + " 00000400 00000001 00000000 00000003 eth0\n", []string{"eth0"}},
+		{"unreachable on loopback", zero + " 00 " + zero + " 00 " + zero + " ffffffff 00000001 00000000 00200200 lo\n", nil},
+		{"prefix", prefix + " 40 " + zero + " 00 " + zero + " 00000100 00000001 00000000 00000001 eth0\n", nil},
+		{"host route", loopback + " 80 " + zero + " 00 " + zero + " 00000000 00000002 00000000 80200001 lo\n", nil},
+		{"down", zero + " 00 " + zero + " 00 " + gateway + " 00000400 00000001 00000000 00000002 eth0\n", nil},
+	} {
+		got, err := defaultRoutes6(strings.NewReader(tt.table))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: defaultRoutes6 = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSwapDevices(t *testing.T) {
+	const header = "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"
+	for _, tt := range []struct {
+		name  string
+		swaps string
+		want  []string
+	}{
+		{"none", header, nil},
+		{"empty file", "", nil},
+		{"two", header +
+			"/dev/sda2                               partition\t8388604\t\t0\t\t-2\n" +
+			"/dev/zram0                              partition\t4194300\t\t0\t\t100\n", []string{"/dev/sda2", "/dev/zram0"}},
+		{"file", header + "/swapfile                               file\t\t2097148\t\t0\t\t-2\n\n", []string{"/swapfile"}},
+	} {
+		got, err := swapDevices(strings.NewReader(tt.swaps))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: swapDevices = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if !swapEncrypted("/dev/zram0") {
+		t.Error("swapEncrypted(/dev/zram0) = false")
+	}
+}
+
+func TestContainerCgroup(t *testing.T) {
+	for _, tt := range []struct {
+		cgroup string
+		want   bool
+	}{
+		{"0::/init.scope\n", false},
+		{"0::/\n", false},
+		{"12:cpuset:/docker/3f2a9c\n11:memory:/docker/3f2a9c\n", true},
+		{"0::/system.slice/containerd.service\n", true},
+		{"0::/kubepods/besteffort/pod1234\n", true},
+		{"0::/lxc.payload.web\n", true},
+		{"0::/machine.slice/libpod-1234.scope\n", true},
+	} {
+		if got, err := containerCgroup(strings.NewReader(tt.cgroup)); err != nil || got != tt.want {
+			t.Errorf("containerCgroup(%q) = %v, %v, want %v", tt.cgroup, got, err, tt.want)
+		}
+	}
+}
+
+func TestHypervisorVendor(t *testing.T) {
+	for _, tt := range []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"Dell Inc.", "OptiPlex 7090"}, ""},
+		{[]string{"LENOVO", "20XW0026GE"}, ""},
+		{[]string{"QEMU", "Standard PC (Q35 + ICH9, 2009)"}, "QEMU"},
+		{[]string{"innotek GmbH", "VirtualBox"}, "innotek GmbH"},
+		{[]string{"Microsoft Corporation", "Virtual Machine"}, "Microsoft Corporation"},
+		{[]string{"VMware, Inc.", "VMware Virtual Platform"}, "VMware, Inc."},
+	} {
+		if got := hypervisorVendor(tt.names); got != tt.want {
+			t.Errorf("hypervisorVendor(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestHypervisorFlag(t *testing.T) {
+	const bare = "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: fpu vme de pse tsc msr\n"
+	const guest = "processor\t: 0\nvendor_id\t: GenuineIntel\nflags\t\t: fpu vme de pse tsc msr hypervisor lahf_lm\n"
+	const secondOnly = bare + "\nprocessor\t: 1\nflags\t\t: fpu hypervisor\n"
+	for _, tt := range []struct {
+		name    string
+		cpuinfo string
+		want    bool
+	}{
+		{"bare metal", bare, false},
+		{"guest", guest, true},
+		{"only the first CPU counts", secondOnly, false},
+		{"no flags", "processor\t: 0\n", false},
+		{"hypervisor as a prefix", "flags\t\t: hypervisor_x\n", false},
+	} {
+		if got, err := hypervisorFlag(strings.NewReader(tt.cpuinfo)); err != nil || got != tt.want {
+			t.Errorf("%s: hypervisorFlag = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestTailsRelease(t *testing.T) {
+	for _, tt := range []struct {
+		release string
+		want    bool
+	}{
+		{"NAME=\"Tails\"\nID=\"tails\"\nID_LIKE=\"debian\"\n", true},
+		{"NAME=\"Tails\"\nID=tails\n", true},
+		{"NAME=\"Debian GNU/Linux\"\nID=debian\n", false},
+		{"ID_LIKE=tails\n", false},
+		{"", false},
+	} {
+		if got, err := tailsRelease(strings.NewReader(tt.release)); err != nil || got != tt.want {
+			t.Errorf("tailsRelease(%q) = %v, %v, want %v", tt.release, got, err, tt.want)
+		}
+	}
+}
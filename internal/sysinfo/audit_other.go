@@ -0,0 +1,11 @@
+//go:build !linux
+
+package sysinfo
+
+func audit() []Finding {
+	return []Finding{{
+		Check:  "Environment",
+		Status: Warn,
+		Detail: "the environment can only be audited on Linux; make sure this machine is offline",
+	}}
+}
@@ -0,0 +1,72 @@
+package sysinfo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// availableMemory returns the smaller of the memory available on the
+// machine and the room left under the cgroup v2 memory limit, if any.
+func availableMemory() (uint64, bool) {
+	available, err := parseFile("/proc/meminfo", meminfoAvailable)
+	if err != nil {
+		return 0, false
+	}
+	// The cgroup v2 hierarchy mounted at /sys/fs/cgroup is, inside a
+	// container, the container's own cgroup.
+	limit, _ := os.ReadFile("/sys/fs/cgroup/memory.max")
+	used, _ := os.ReadFile("/sys/fs/cgroup/memory.current")
+	return cgroupAvailable(available, string(limit), string(used)), true
+}
+
+// cgroupAvailable limits available to the room left under a cgroup v2
+// memory limit, given the contents of memory.max and memory.current. A limit
+// of "max" or one that cannot be read leaves available unchanged, and a
+// cgroup at its limit has no room left.
+func cgroupAvailable(available uint64, limit, used string) uint64 {
+	most, ok := parseCgroupValue(limit)
+	if !ok {
+		return available
+	}
+	current, _ := parseCgroupValue(used)
+	return min(available, most-min(current, most))
+}
+
+// parseCgroupValue parses a number from a cgroup v2 file. ok is false for
+// "max" and for empty or malformed contents.
+func parseCgroupValue(data string) (value uint64, ok bool) {
+	value, err := strconv.ParseUint(strings.TrimSpace(data), 10, 64)
+	return value, err == nil
+}
+
+// meminfoAvailable reads MemAvailable from /proc/meminfo. Kernels older
+// than 3.14 lack it, so free memory plus the page cache is used instead.
+func meminfoAvailable(r io.Reader) (uint64, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return 0, err
+	}
+	fields := map[string]uint64{}
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		kib, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		fields[name] = kib * 1024
+	}
+	if available, ok := fields["MemAvailable"]; ok {
+		return available, nil
+	}
+	free, ok := fields["MemFree"]
+	if !ok {
+		return 0, errors.New("meminfo has neither MemAvailable nor MemFree")
+	}
+	return free + fields["Buffers"] + fields["Cached"], nil
+}
@@ -0,0 +1,50 @@
+package sysinfo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMeminfoAvailable(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		meminfo string
+		want    uint64
+		ok      bool
+	}{
+		{"MemAvailable", "MemTotal:       16318412 kB\nMemFree:         1234567 kB\nMemAvailable:    8000000 kB\nBuffers:          200000 kB\nCached:          5000000 kB\n", 8000000 * 1024, true},
+		{"old kernel", "MemTotal:       16318412 kB\nMemFree:         1000000 kB\nBuffers:          200000 kB\nCached:          3000000 kB\n", 4200000 * 1024, true},
+		{"free only", "MemFree:         1000000 kB\n", 1000000 * 1024, true},
+		{"malformed lines", "garbage\nMemAvailable: lots kB\nMemFree: 10 kB\n", 10 * 1024, true},
+		{"empty", "", 0, false},
+		{"no free memory line", "MemTotal:       16318412 kB\n", 0, false},
+	} {
+		got, err := meminfoAvailable(strings.NewReader(tt.meminfo))
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s: meminfoAvailable = %d, %v, want %d, ok %v", tt.name, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCgroupAvailable(t *testing.T) {
+	const gib = 1 << 30
+	for _, tt := range []struct {
+		name          string
+		limit, used   string
+		available     uint64
+		wantAvailable uint64
+	}{
+		{"no cgroup", "", "", 8 * gib, 8 * gib},
+		{"unlimited", "max\n", "1073741824\n", 8 * gib, 8 * gib},
+		{"room under the limit", "4294967296\n", "1073741824\n", 8 * gib, 3 * gib},
+		{"limit above the machine", "17179869184\n", "1073741824\n", 8 * gib, 8 * gib},
+		{"limit reached", "1073741824\n", "1073741824\n", 8 * gib, 0},
+		{"over the limit", "1073741824\n", "2147483648\n", 8 * gib, 0},
+		{"usage unknown", "2147483648\n", "", 8 * gib, 2 * gib},
+		{"malformed limit", "lots\n", "0\n", 8 * gib, 8 * gib},
+	} {
+		if got := cgroupAvailable(tt.available, tt.limit, tt.used); got != tt.wantAvailable {
+			t.Errorf("%s: cgroupAvailable = %d, want %d", tt.name, got, tt.wantAvailable)
+		}
+	}
+}
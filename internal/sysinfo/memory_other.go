@@ -0,0 +1,7 @@
+//go:build !linux
+
+package sysinfo
+
+func availableMemory() (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,10 @@
+// Package sysinfo reports properties of the machine the program runs on
+// that affect whether it is safe or possible to derive a key.
+package sysinfo
+
+// AvailableMemory returns an estimate of the memory, in bytes, that can be
+// allocated without swapping. ok is false when the platform gives no
+// estimate.
+func AvailableMemory() (bytes uint64, ok bool) {
+	return availableMemory()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"walletscrambler/scrambler"
+)
+
+// progressBarWidth is the number of cells in the SHA3 progress bar.
+const progressBarWidth = 30
+
+// progressFormats lists the values of the -progress flag.
+var progressFormats = []string{"auto", "none", "text", "json"}
+
+func validProgressFormat(format string) bool {
+	for _, f := range progressFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// progressEvent is one line of the JSON progress stream.
+type progressEvent struct {
+	Phase     string  `json:"phase"`
+	Done      int     `json:"done"`
+	Total     int     `json:"total"`
+	Percent   float64 `json:"percent"`
+	ElapsedMS int64   `json:"elapsed_ms"`
+	ETAMS     int64   `json:"eta_ms,omitempty"`
+}
+
+// progressReporter returns a function for scrambler.WithProgress that
+// writes the key derivation progress to w as a progress bar ("text"), as
+// JSON lines ("json") or not at all ("none"). "auto" selects text when w is
+// a terminal.
+func progressReporter(w io.Writer, format string) func(scrambler.Progress) {
+	if format == "auto" {
+		format = "none"
+		if f, ok := w.(*os.File); ok && terminalFd(f) >= 0 {
+			format = "text"
+		}
+	}
+	switch format {
+	case "text":
+		return func(p scrambler.Progress) { fmt.Fprint(w, progressLine(p)) }
+	case "json":
+		enc := json.NewEncoder(w)
+		return func(p scrambler.Progress) {
+			enc.Encode(progressEvent{
+				Phase:     p.Phase.String(),
+				Done:      p.Done,
+				Total:     p.Total,
+				Percent:   progressPercent(p),
+				ElapsedMS: p.Elapsed.Milliseconds(),
+				ETAMS:     p.ETA().Milliseconds(),
+			})
+		}
+	}
+	return nil
+}
+
+// progressLine renders p as a single terminal line that overwrites the
+// previous one, ending with a newline once the key is derived.
+func progressLine(p scrambler.Progress) string {
+	switch p.Phase {
+	case scrambler.PhaseSHA3:
+		filled := int(progressPercent(p) / 100 * progressBarWidth)
+		bar := strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled)
+		eta := "estimating"
+		if p.Done > 0 {
+			eta = "ETA " + formatDuration(p.ETA())
+		}
+		return fmt.Sprintf("\rStretching salt [%s] %3.0f%%  %s\033[K", bar, progressPercent(p), eta)
+	case scrambler.PhaseArgon2:
+		return fmt.Sprintf("\rStretching salt done. Running Argon2id, %s elapsed\033[K", formatDuration(p.Elapsed))
+	default:
+		return fmt.Sprintf("\rKey derived in %s\033[K\n", formatDuration(p.Elapsed))
+	}
+}
+
+func progressPercent(p scrambler.Progress) float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return 100 * float64(p.Done) / float64(p.Total)
+}
+
+// formatDuration rounds d to whole seconds for display.
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
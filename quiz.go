@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"walletscrambler/scrambler"
+)
+
+// minQuizWords is the fewest words a random selection asks for, unless
+// fewer were written down.
+const minQuizWords = 4
+
+// transcript is what the user was told to write down after scrambling.
+type transcript struct {
+	salt  []string
+	slots [][]string // scrambled words: one slot, or two for a duress backup
+	check [][]string // check words of each slot, possibly empty
+}
+
+// quizWord is a word of a transcript the user is asked to re-enter.
+type quizWord struct {
+	name  string
+	words []string // the list in the copy being built that holds the word
+	index int
+}
+
+// clone returns a deep copy of t.
+func (t transcript) clone() transcript {
+	c := transcript{salt: append([]string(nil), t.salt...)}
+	for i := range t.slots {
+		c.slots = append(c.slots, append([]string(nil), t.slots[i]...))
+		c.check = append(c.check, append([]string(nil), t.check[i]...))
+	}
+	return c
+}
+
+// quizWords lists every word of t in the order it was printed.
+func (t transcript) quizWords() []quizWord {
+	var all []quizWord
+	add := func(kind string, words []string) {
+		for i := range words {
+			all = append(all, quizWord{fmt.Sprintf("%s %d", kind, i+1), words, i})
+		}
+	}
+	add("salt word", t.salt)
+	for i := range t.slots {
+		slot := ""
+		if len(t.slots) > 1 {
+			slot = fmt.Sprintf("slot %d ", i+1)
+		}
+		add(slot+"word", t.slots[i])
+		add(slot+"check word", t.check[i])
+	}
+	return all
+}
+
+// quizNote is shown with the words before verifyTranscript runs.
+const quizNote = "\n{yellow}Once you are done, the screen is cleared and you re-enter words from your\n" +
+	"{yellow}written copy and your password, so a mis-copied word is found now and not when\n" +
+	"{yellow}you need the backup.\n"
+
+// verifyTranscript makes sure the words were written down correctly, once
+// they have been shown and cleared from the screen. The user re-enters every
+// word, or a random selection, from the written copy; once they all match,
+// roundTrip derives the key again from the re-entered salt and unscrambles
+// the copy, which must reproduce the original wallet. Until that succeeds,
+// display shows the words again to correct the copy and the user is asked
+// again.
+func verifyTranscript(p *prompter, list scrambler.Wordlist, written transcript, display func() error, roundTrip func(transcript) error) error {
+	for {
+		all, err := choice(p, "Do you want to re-enter every word or a random selection?", "Every word", "Random selection", "E", "R")
+		if err != nil {
+			return err
+		}
+		entered := written.clone()
+		asked := entered.quizWords()
+		if !all {
+			if asked, err = randomSelection(asked); err != nil {
+				return err
+			}
+		}
+		printStyled("\n{cyan}Enter the words from your written copy.\n")
+		var wrong []string
+		for _, q := range asked {
+			word, err := readQuizWord(p, list, q.name)
+			if err != nil {
+				return err
+			}
+			if word != q.words[q.index] {
+				wrong = append(wrong, q.name)
+			}
+			q.words[q.index] = word
+		}
+
+		if len(wrong) == 0 {
+			err = roundTrip(entered)
+		}
+		for _, words := range append(entered.slots, entered.check...) {
+			clearWords(words)
+		}
+		if len(wrong) == 0 && err == nil {
+			printStyled("\n{green}Your written copy is correct: it unscrambles to your wallet.\n")
+			return nil
+		}
+		if len(wrong) > 0 {
+			printStyled("\n{red}These words do not match: " + strings.Join(wrong, ", ") + ".\n")
+		} else if errors.Is(err, context.Canceled) {
+			return err
+		} else {
+			printStyled("\n{red}Your written copy does not unscramble to your wallet with the password you entered: " + err.Error() + "\n")
+		}
+		printStyled("{yellow}Correct your written copy from the words shown next.\n")
+		if err := pressAnyKey(p); err != nil {
+			return err
+		}
+		if err := display(); err != nil {
+			return err
+		}
+	}
+}
+
+// readQuizWord asks for the word called name until one from list is given.
+func readQuizWord(p *prompter, list scrambler.Wordlist, name string) (string, error) {
+	for {
+		fmt.Printf("Enter %s: ", name)
+		word, hint, err := p.word(list)
+		if err != nil || word != "" {
+			return word, err
+		}
+		printStyled("{red}Invalid word. Please enter a valid word from the wordlist.\n")
+		if hint != "" {
+			printStyled("{yellow}" + hint + "\n")
+		}
+	}
+}
+
+// randomSelection picks a third of words, at least minQuizWords of them,
+// keeping their order.
+func randomSelection(words []quizWord) ([]quizWord, error) {
+	n := min(len(words), max(minQuizWords, (len(words)+2)/3))
+	picked := make([]int, len(words))
+	for i := range picked {
+		picked[i] = i
+	}
+	// Partial Fisher-Yates shuffle of the first n positions.
+	for i := 0; i < n; i++ {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(len(words)-i)))
+		if err != nil {
+			return nil, fmt.Errorf("generating random index: %w", err)
+		}
+		k := i + int(j.Int64())
+		picked[i], picked[k] = picked[k], picked[i]
+	}
+	picked = picked[:n]
+	sort.Ints(picked)
+	selection := make([]quizWord, n)
+	for i, index := range picked {
+		selection[i] = words[index]
+	}
+	return selection, nil
+}
+
+// sameWords reports whether a and b hold the same words in the same order.
+func sameWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// errRoundTrip is returned by a transcript round trip that does not give
+// back the original wallet.
+var errRoundTrip = errors.New("the words unscramble to a different wallet")
+
+// openMatches opens the slot of b that key unlocks and checks that it holds
+// want.
+func openMatches(key *scrambler.Key, b *scrambler.DuressBackup, want []string) error {
+	words, err := key.OpenDuress(b)
+	defer clearWords(words)
+	if err != nil {
+		return err
+	}
+	if !sameWords(words, want) {
+		return errRoundTrip
+	}
+	return nil
+}
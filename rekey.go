@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"walletscrambler/internal/secmem"
+	"walletscrambler/scrambler"
+)
+
+// rekeyOptions holds the flags of the rekey command.
+type rekeyOptions struct {
+	mode            scrambler.Mode
+	profile         scrambler.KDFProfile
+	newProfile      scrambler.KDFProfile
+	label           string
+	newLabel        string
+	words           string
+	salt            string
+	newSalt         string
+	newSaltCount    int
+	saltSource      saltSourceOptions
+	count           int
+	check           string
+	noVerify        bool
+	checkWords      int
+	passwordFile    string
+	newPasswordFile string
+	format          string
+	progress        string
+	audit           auditOptions
+}
+
+// runRekey re-scrambles words under a new password, salt or KDF profile
+// without ever printing the original wallet words.
+func runRekey(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts rekeyOptions
+	var modeName, profileID, newProfileID string
+	fs := flag.NewFlagSet("rekey", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&modeName, "mode", scrambler.ModeWords.Name(), "how wallet words are interpreted: "+modeNames())
+	fs.StringVar(&profileID, "profile", scrambler.DefaultProfile, "KDF profile the words were scrambled with: "+profileIDs())
+	fs.StringVar(&newProfileID, "new-profile", "", "KDF profile to re-scramble with (default: -profile)")
+	fs.StringVar(&opts.label, "label", "", "wallet label the words were scrambled with")
+	fs.StringVar(&opts.newLabel, "new-label", "", "wallet label to re-scramble with (default: -label)")
+	fs.StringVar(&opts.words, "words", "", "scrambled words separated by spaces or commas (default: read from stdin)")
+	fs.StringVar(&opts.salt, "salt", "", "salt words the words were scrambled with")
+	fs.StringVar(&opts.newSalt, "new-salt", "", "new salt words (default: keep the old salt)")
+	fs.IntVar(&opts.newSaltCount, "new-salt-count", 0, "number of new salt words to generate")
+	opts.saltSource.register(fs, "-new-salt-count")
+	fs.IntVar(&opts.count, "count", 0, "expected number of wallet words (0 accepts any valid count)")
+	fs.StringVar(&opts.check, "check", "", "check words of the scrambled words, to detect a wrong old password or salt")
+	fs.BoolVar(&opts.noVerify, "no-verify", false, "re-scramble without -check; a wrong old password or salt then silently gives a backup that cannot be recovered")
+	fs.IntVar(&opts.checkWords, "check-words", 0, fmt.Sprintf("number of check words to emit for the new words (0 to %d)", scrambler.MaxCheckWords))
+	fs.StringVar(&opts.passwordFile, "password-file", "", "read the old password from the first line of `file`")
+	fs.StringVar(&opts.newPasswordFile, "new-password-file", "", "read the new password from the first line of `file`")
+	fs.StringVar(&opts.format, "format", "text", "output format: text or json")
+	fs.StringVar(&opts.progress, "progress", "auto", "key derivation progress on stderr: "+strings.Join(progressFormats, ", "))
+	opts.audit.register(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "rekey: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+	mode, ok := scrambler.ModeByName(modeName)
+	if !ok {
+		fmt.Fprintf(stderr, "rekey: unknown mode %q\n", modeName)
+		return exitUsage
+	}
+	opts.mode = mode
+	if newProfileID == "" {
+		newProfileID = profileID
+	}
+	if opts.newLabel == "" {
+		opts.newLabel = opts.label
+	}
+	for _, err := range []error{checkLabel("-label", opts.label), checkLabel("-new-label", opts.newLabel)} {
+		if err != nil {
+			fmt.Fprintf(stderr, "rekey: %v\n", err)
+			return exitUsage
+		}
+	}
+	profile, ok := scrambler.LookupProfile(profileID)
+	if !ok {
+		fmt.Fprintf(stderr, "rekey: unknown KDF profile %q\n", profileID)
+		return exitUsage
+	}
+	opts.profile = profile
+	if opts.newProfile, ok = scrambler.LookupProfile(newProfileID); !ok {
+		fmt.Fprintf(stderr, "rekey: unknown KDF profile %q\n", newProfileID)
+		return exitUsage
+	}
+	for _, profile := range []scrambler.KDFProfile{opts.profile, opts.newProfile} {
+		if err := checkMemory(profile); err != nil {
+			fmt.Fprintf(stderr, "rekey: %v\n", err)
+			return exitError
+		}
+	}
+	if opts.format != "text" && opts.format != "json" {
+		fmt.Fprintf(stderr, "rekey: unknown format %q\n", opts.format)
+		return exitUsage
+	}
+	if !validProgressFormat(opts.progress) {
+		fmt.Fprintf(stderr, "rekey: unknown progress format %q\n", opts.progress)
+		return exitUsage
+	}
+	if opts.newSalt != "" && opts.newSaltCount > 0 {
+		fmt.Fprintln(stderr, "rekey: -new-salt and -new-salt-count are mutually exclusive")
+		return exitUsage
+	}
+	if opts.newSaltCount < 0 || opts.newSaltCount > scrambler.MaxSaltWords {
+		fmt.Fprintf(stderr, "rekey: -new-salt-count must be between 0 and %d\n", scrambler.MaxSaltWords)
+		return exitUsage
+	}
+	if err := opts.saltSource.check("-new-salt-count", opts.newSaltCount); err != nil {
+		fmt.Fprintf(stderr, "rekey: %v\n", err)
+		return exitUsage
+	}
+	if opts.check == "" && !opts.noVerify {
+		fmt.Fprintln(stderr, "rekey: -check is required to verify the old password and salt; pass -no-verify to re-scramble without it")
+		return exitUsage
+	}
+	if opts.check != "" && opts.noVerify {
+		fmt.Fprintln(stderr, "rekey: -check and -no-verify are mutually exclusive")
+		return exitUsage
+	}
+	if opts.checkWords < 0 || opts.checkWords > scrambler.MaxCheckWords {
+		fmt.Fprintf(stderr, "rekey: -check-words must be between 0 and %d\n", scrambler.MaxCheckWords)
+		return exitUsage
+	}
+
+	if err := auditCommand("rekey", opts.audit, stderr); err != nil {
+		fmt.Fprintf(stderr, "rekey: %v\n", err)
+		return exitError
+	}
+	if err := rekey(opts, stdin, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "rekey: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func rekey(opts rekeyOptions, in io.Reader, stdout, stderr io.Writer) error {
+	progress := scrambler.WithProgress(progressReporter(stderr, opts.progress))
+	from := scrambler.New(scrambler.WithMode(opts.mode), scrambler.WithProfile(opts.profile), scrambler.WithLabel(opts.label), progress)
+	to := scrambler.New(scrambler.WithMode(opts.mode), scrambler.WithProfile(opts.newProfile), scrambler.WithLabel(opts.newLabel), progress)
+	stdin := newPrompter(in, stderr)
+	defer stdin.wipe()
+
+	password, err := readPassword(opts.passwordFile, stdin, "Old password: ")
+	if err != nil {
+		return fmt.Errorf("old password: %w", err)
+	}
+	newPassword, err := readPassword(opts.newPasswordFile, stdin, "New password: ")
+	if err != nil {
+		return fmt.Errorf("new password: %w", err)
+	}
+	if opts.newPasswordFile == "" {
+		confirmation, err := readPassword("", stdin, "Confirm the new password: ")
+		if err != nil {
+			return fmt.Errorf("new password confirmation: %w", err)
+		}
+		if !bytes.Equal(newPassword, confirmation) {
+			return errors.New("the new passwords do not match")
+		}
+	}
+	newSaltWords, err := opts.saltSource.generate(stdin, to.Wordlist(), opts.newSaltCount)
+	if err != nil {
+		return err
+	}
+	if isWeakPassword(newPassword) {
+		fmt.Fprintln(stderr, "warning: the new password is weak")
+	}
+	if opts.newLabel == "" {
+		fmt.Fprintln(stderr, noLabelWarning)
+	}
+
+	var words []string
+	if opts.words != "" {
+		words = splitWords(opts.words, from.Wordlist())
+	} else {
+		data := secmem.New(maxInputLen)
+		_, err := data.ReadFrom(in)
+		words = internWords(data.Bytes(), from.Wordlist())
+		data.Destroy()
+		if err != nil {
+			return fmt.Errorf("reading scrambled words: %w", err)
+		}
+	}
+	if opts.count > 0 && len(words) != opts.count {
+		return fmt.Errorf("expected %d wallet words, got %d", opts.count, len(words))
+	}
+	if err := from.ValidateWords(words); err != nil {
+		return err
+	}
+	check := splitWords(opts.check, from.Wordlist())
+	if len(check) > scrambler.MaxCheckWords {
+		return fmt.Errorf("got %d check words, expected at most %d", len(check), scrambler.MaxCheckWords)
+	}
+	for i, word := range check {
+		if !from.Wordlist().Contains(word) {
+			return fmt.Errorf("check word %d (%q) is not in the wordlist", i+1, word)
+		}
+	}
+
+	saltWords := splitWords(opts.salt, from.Wordlist())
+	if opts.newSalt != "" {
+		newSaltWords = splitWords(opts.newSalt, to.Wordlist())
+	} else if opts.newSaltCount == 0 {
+		newSaltWords = saltWords
+	}
+	if bytes.Equal(password, newPassword) && opts.profile.ID == opts.newProfile.ID && opts.label == opts.newLabel &&
+		strings.Join(saltWords, " ") == strings.Join(newSaltWords, " ") {
+		return errors.New("the new password, salt, label and KDF profile are the same as the old ones")
+	}
+
+	fmt.Fprintln(stderr, "Deriving the old and the new key, this takes twice as long as scrambling...")
+	oldKey, err := from.DeriveKey(password, saltWords)
+	if err != nil {
+		return err
+	}
+	defer oldKey.Wipe()
+	if len(check) > 0 {
+		if err := oldKey.VerifyCheckWords(words, check); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintln(stderr, "warning: -no-verify given; the old password and salt are not checked")
+	}
+	newKey, err := to.DeriveKey(newPassword, newSaltWords)
+	if err != nil {
+		return err
+	}
+	defer newKey.Wipe()
+
+	result := transformResult{Mode: opts.mode.Name(), Profile: opts.newProfile.ID, Label: opts.newLabel, Salt: newSaltWords}
+	if result.Words, err = scrambler.Rekey(oldKey, newKey, words); err != nil {
+		return err
+	}
+	if opts.checkWords > 0 {
+		if result.Check, err = newKey.CheckWords(result.Words, opts.checkWords); err != nil {
+			return err
+		}
+	}
+	return writeResult(stdout, opts.format, result)
+}
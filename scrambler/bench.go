@@ -0,0 +1,172 @@
+package scrambler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// benchSHA3Rounds is the largest SHA3 sample MeasureKDF times.
+	benchSHA3Rounds = 1 << 20
+	// benchArgon2Passes is the largest number of Argon2id passes MeasureKDF
+	// times in its second run.
+	benchArgon2Passes = 3
+)
+
+// CustomPrefix starts the ID of every custom profile. A custom profile is
+// not registered: its ID spells out its parameters, so writing the ID down
+// with a backup is enough to recover it.
+const CustomPrefix = "custom-"
+
+// The weakest custom profile accepted has the parameters of lowmem-v1: at
+// least its SHA3 rounds and Argon2id memory, and at least as much Argon2id
+// work, counted as passes times memory. Anything weaker could only be chosen
+// by mistake.
+const (
+	minCustomSHA3Rounds = 4847868
+	minCustomMemory     = 256 * 1024
+	minCustomWork       = 256 * minCustomMemory
+)
+
+// Benchmark is the measured speed of the key derivation stages of a profile
+// on this machine.
+type Benchmark struct {
+	Profile     KDFProfile
+	SHA3Rounds  int           // SHA3 rounds timed
+	SHA3Time    time.Duration // time taken by SHA3Rounds rounds
+	Argon2Setup time.Duration // Argon2id time not spent in passes, mostly allocation
+	Argon2Pass  time.Duration // time taken by one Argon2id pass
+}
+
+// MeasureKDF times a sample of each key derivation stage of profile.
+// Argon2id is run once with a single pass and once with a few, to tell the
+// cost of a pass from the fixed cost of allocating its memory. It uses the
+// profile's full memory and threads, so the machine needs as much memory as
+// a real derivation. It stops early with the context's error when ctx is
+// cancelled.
+func MeasureKDF(ctx context.Context, profile KDFProfile) (Benchmark, error) {
+	if err := profile.validate(); err != nil {
+		return Benchmark{}, err
+	}
+	b := Benchmark{Profile: profile, SHA3Rounds: min(profile.SHA3Rounds, benchSHA3Rounds)}
+	start := time.Now()
+	seed, err := hashRepeatedly(ctx, []byte(emptySalt), b.SHA3Rounds, nil)
+	if err != nil {
+		return Benchmark{}, err
+	}
+	b.SHA3Time = time.Since(start)
+
+	timeArgon2 := func(passes uint32) time.Duration {
+		start := time.Now()
+		zero(argon2.IDKey([]byte("benchmark"), seed, passes, profile.Argon2Memory, profile.Argon2Threads, profile.KeyLen))
+		return time.Since(start)
+	}
+	single := timeArgon2(1)
+	b.Argon2Pass = single
+	if passes := min(profile.Argon2Time, benchArgon2Passes); passes > 1 {
+		if err := ctx.Err(); err != nil {
+			return Benchmark{}, err
+		}
+		b.Argon2Pass = max((timeArgon2(passes)-single)/time.Duration(passes-1), 1)
+		b.Argon2Setup = max(single-b.Argon2Pass, 0)
+	}
+	if err := ctx.Err(); err != nil {
+		return Benchmark{}, err
+	}
+	return b, nil
+}
+
+// Estimate extrapolates the time each stage of a full derivation with the
+// measured profile takes.
+func (b Benchmark) Estimate() (sha3, argon2 time.Duration) {
+	sha3 = time.Duration(float64(b.SHA3Time) * float64(b.Profile.SHA3Rounds) / float64(b.SHA3Rounds))
+	argon2 = b.Argon2Setup + b.Argon2Pass*time.Duration(b.Profile.Argon2Time)
+	return sha3, argon2
+}
+
+// Calibrate proposes a custom profile whose derivation takes about target on
+// this machine. Memory and threads are kept from the measured profile and
+// the SHA3 rounds and Argon2id passes are scaled so both stages keep their
+// share of the total. A target too short for the minimum strength of a
+// custom profile is an error.
+func (b Benchmark) Calibrate(target time.Duration) (KDFProfile, error) {
+	sha3, argon2 := b.Estimate()
+	if target <= 0 || sha3+argon2 <= 0 || b.Argon2Pass <= 0 {
+		return KDFProfile{}, fmt.Errorf("cannot calibrate for %v", target)
+	}
+	scale := float64(target) / float64(sha3+argon2)
+	rounds := max(int(float64(b.Profile.SHA3Rounds)*scale+0.5), 1)
+	argon2Target := time.Duration(float64(argon2) * scale)
+	passes := max(float64(argon2Target-b.Argon2Setup)/float64(b.Argon2Pass)+0.5, 1)
+	if passes > math.MaxUint32 {
+		return KDFProfile{}, fmt.Errorf("cannot calibrate for %v: too many Argon2id passes", target)
+	}
+	p, err := CustomProfile(rounds, uint32(passes), b.Profile.Argon2Memory, b.Profile.Argon2Threads)
+	if err != nil {
+		return KDFProfile{}, fmt.Errorf("cannot calibrate for %v: %w", target, err)
+	}
+	return p, nil
+}
+
+// CustomProfile returns an unregistered profile with the given parameters
+// and a key length of 64 bytes. Its ID has the form
+// custom-s<rounds>-t<passes>-m<memory KiB>-p<threads>. Parameters weaker
+// than those of lowmem-v1 are rejected.
+func CustomProfile(rounds int, passes, memory uint32, threads uint8) (KDFProfile, error) {
+	p := KDFProfile{
+		ID:            fmt.Sprintf("%ss%d-t%d-m%d-p%d", CustomPrefix, rounds, passes, memory, threads),
+		SHA3Rounds:    rounds,
+		Argon2Time:    passes,
+		Argon2Memory:  memory,
+		Argon2Threads: threads,
+		KeyLen:        64,
+	}
+	if err := p.validate(); err != nil {
+		return KDFProfile{}, err
+	}
+	switch {
+	case rounds < minCustomSHA3Rounds:
+		return KDFProfile{}, fmt.Errorf("KDF profile %q: at least %d SHA3 rounds are required", p.ID, minCustomSHA3Rounds)
+	case memory < minCustomMemory:
+		return KDFProfile{}, fmt.Errorf("KDF profile %q: at least %d KiB of Argon2id memory are required", p.ID, minCustomMemory)
+	case uint64(passes)*uint64(memory) < minCustomWork:
+		return KDFProfile{}, fmt.Errorf("KDF profile %q: Argon2id passes times memory must be at least %d KiB", p.ID, minCustomWork)
+	}
+	return p, nil
+}
+
+// parseCustomProfile rebuilds a custom profile from its ID. Only the exact
+// form produced by CustomProfile is accepted, so every profile has a single
+// ID.
+func parseCustomProfile(id string) (KDFProfile, bool) {
+	fields := strings.Split(strings.TrimPrefix(id, CustomPrefix), "-")
+	if !strings.HasPrefix(id, CustomPrefix) || len(fields) != 4 {
+		return KDFProfile{}, false
+	}
+	var values [4]uint64
+	for i, field := range []struct {
+		prefix string
+		bits   int
+	}{{"s", 63}, {"t", 32}, {"m", 32}, {"p", 8}} {
+		digits, ok := strings.CutPrefix(fields[i], field.prefix)
+		if !ok {
+			return KDFProfile{}, false
+		}
+		v, err := strconv.ParseUint(digits, 10, field.bits)
+		if err != nil || uint64(int(v)) != v {
+			return KDFProfile{}, false
+		}
+		values[i] = v
+	}
+	p, err := CustomProfile(int(values[0]), uint32(values[1]), uint32(values[2]), uint8(values[3]))
+	if err != nil || p.ID != id {
+		return KDFProfile{}, false
+	}
+	return p, true
+}
@@ -0,0 +1,83 @@
+package scrambler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCustomProfile(t *testing.T) {
+	p, err := CustomProfile(5000000, 128, 512*1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "custom-s5000000-t128-m524288-p2" {
+		t.Errorf("ID = %q", p.ID)
+	}
+	got, ok := LookupProfile(p.ID)
+	if !ok || got != p {
+		t.Errorf("LookupProfile(%q) = %+v, %v, want %+v", p.ID, got, ok, p)
+	}
+	for _, id := range []string{
+		"custom-",
+		"custom-s5000000-t128-m524288",
+		"custom-s5000000-t128-m524288-p2-x",
+		"custom-s05000000-t128-m524288-p2",
+		"custom-s5000000-t0-m524288-p2",
+		"custom-s5000000-t128-m524288-p256",
+		"custom-t128-s5000000-m524288-p2",
+		"custom-s+5000000-t128-m524288-p2",
+		"custom-s1000-t1-m64-p1",          // the test profile
+		"custom-s4847867-t256-m262144-p4", // too few SHA3 rounds
+		"custom-s4847868-t512-m131072-p4", // too little memory
+		"custom-s4847868-t255-m262144-p4", // too little Argon2id work
+	} {
+		if _, ok := LookupProfile(id); ok {
+			t.Errorf("LookupProfile(%q) succeeded", id)
+		}
+	}
+	lowmem, _ := LookupProfile("lowmem-v1")
+	if _, err := CustomProfile(lowmem.SHA3Rounds, lowmem.Argon2Time, lowmem.Argon2Memory, lowmem.Argon2Threads); err != nil {
+		t.Errorf("the parameters of lowmem-v1 are rejected: %v", err)
+	}
+	if err := RegisterProfile(KDFProfile{ID: "custom-mine", SHA3Rounds: 1, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1, KeyLen: 64}); err == nil {
+		t.Error("registered a profile with a reserved ID")
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	b := Benchmark{
+		Profile:     KDFProfile{ID: "v1", SHA3Rounds: 8000000, Argon2Time: 64, Argon2Memory: 1024 * 1024, Argon2Threads: 4, KeyLen: 64},
+		SHA3Rounds:  2000000,
+		SHA3Time:    time.Second,
+		Argon2Setup: 4 * time.Second,
+		Argon2Pass:  time.Second / 2,
+	}
+	sha3, argon2 := b.Estimate()
+	if sha3 != 4*time.Second || argon2 != 36*time.Second {
+		t.Fatalf("Estimate() = %v, %v", sha3, argon2)
+	}
+	p, err := b.Calibrate(80 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SHA3Rounds != 16000000 || p.Argon2Time != 136 || p.Argon2Memory != 1024*1024 || p.Argon2Threads != 4 {
+		t.Errorf("Calibrate(80s) = %+v", p)
+	}
+	if p, err := b.Calibrate(time.Millisecond); err == nil {
+		t.Errorf("Calibrate(1ms) = %+v, want an error", p)
+	}
+	if _, err := b.Calibrate(0); err == nil {
+		t.Error("Calibrate(0) succeeded")
+	}
+}
+
+func TestMeasureKDF(t *testing.T) {
+	b, err := MeasureKDF(context.Background(), testProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.SHA3Rounds != testProfile.SHA3Rounds || b.SHA3Time <= 0 || b.Argon2Pass <= 0 || b.Argon2Setup != 0 {
+		t.Errorf("MeasureKDF(test) = %+v", b)
+	}
+}
@@ -0,0 +1,65 @@
+package scrambler
+
+import "crypto/sha256"
+
+// bip39WordCounts lists the valid BIP39 mnemonic lengths.
+var bip39WordCounts = []int{12, 15, 18, 21, 24}
+
+type bip39Mode struct{}
+
+func (bip39Mode) Name() string       { return "bip39" }
+func (bip39Mode) Wordlist() Wordlist { return BIP39 }
+
+func (bip39Mode) WordCounts() []int { return bip39WordCounts }
+
+func (bip39Mode) CheckWordCount(n int) error {
+	return checkWordCount(n, bip39WordCounts)
+}
+
+func (bip39Mode) validate(indices []uint16) error {
+	entropy, checksum := bip39Split(indices)
+	defer zero(entropy)
+	if bip39Checksum(entropy) != checksum {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// apply XORs the entropy only and appends a freshly computed checksum.
+func (bip39Mode) apply(key []byte, indices []uint16) []uint16 {
+	entropy, _ := bip39Split(indices)
+	defer zero(entropy)
+	xorBytes(entropy, key[:len(entropy)])
+	return bip39Join(entropy)
+}
+
+// bip39Split returns the entropy and checksum encoded by indices. The
+// checksum is one bit for every 32 bits of entropy, so the entropy is always
+// a whole number of bytes.
+func bip39Split(indices []uint16) (entropy []byte, checksum uint16) {
+	wordBitSize := BIP39.BitsPerWord()
+	data := packIndices(indices, wordBitSize)
+	defer zero(data)
+	entropyLen := len(indices) * wordBitSize * 32 / 33
+	entropy = make([]byte, entropyLen/8)
+	copy(entropy, data)
+	return entropy, readBits(data, entropyLen, entropyLen/32)
+}
+
+// bip39Join encodes entropy followed by its checksum as word indices.
+func bip39Join(entropy []byte) []uint16 {
+	entropyLen := len(entropy) * 8
+	data := make([]byte, len(entropy)+1)
+	defer zero(data)
+	copy(data, entropy)
+	writeBits(data, entropyLen, entropyLen/32, bip39Checksum(entropy))
+	wordBitSize := BIP39.BitsPerWord()
+	return unpackIndices(data, (entropyLen+entropyLen/32)/wordBitSize, wordBitSize)
+}
+
+// bip39Checksum returns the leading bits of the SHA-256 digest of entropy.
+func bip39Checksum(entropy []byte) uint16 {
+	digest := sha256.Sum256(entropy)
+	defer zero(digest[:])
+	return uint16(digest[0] >> (8 - len(entropy)/4))
+}
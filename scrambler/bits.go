@@ -0,0 +1,59 @@
+package scrambler
+
+// Bits are numbered from the most significant bit of the first byte, which
+// matches the order in which mnemonic words encode their data.
+
+// readBits returns n bits (at most 16) of data starting at bit offset.
+func readBits(data []byte, offset, n int) uint16 {
+	var value uint16
+	for i := offset; i < offset+n; i++ {
+		value = value<<1 | uint16(data[i/8]>>(7-i%8)&1)
+	}
+	return value
+}
+
+// writeBits stores the low n bits of value in data starting at bit offset.
+func writeBits(data []byte, offset, n int, value uint16) {
+	for i := 0; i < n; i++ {
+		bit := byte(value>>(n-1-i)) & 1
+		pos := offset + i
+		data[pos/8] = data[pos/8]&^(1<<(7-pos%8)) | bit<<(7-pos%8)
+	}
+}
+
+// packIndices concatenates word indices of bitsPerWord bits each.
+func packIndices(indices []uint16, bitsPerWord int) []byte {
+	data := make([]byte, (len(indices)*bitsPerWord+7)/8)
+	for i, index := range indices {
+		writeBits(data, i*bitsPerWord, bitsPerWord, index)
+	}
+	return data
+}
+
+// unpackIndices splits the first count*bitsPerWord bits of data into word
+// indices.
+func unpackIndices(data []byte, count, bitsPerWord int) []uint16 {
+	indices := make([]uint16, count)
+	for i := range indices {
+		indices[i] = readBits(data, i*bitsPerWord, bitsPerWord)
+	}
+	return indices
+}
+
+func xorBytes(dst, key []byte) {
+	for i := range dst {
+		dst[i] ^= key[i]
+	}
+}
+
+func zero(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
+
+func zeroIndices(indices []uint16) {
+	for i := range indices {
+		indices[i] = 0
+	}
+}
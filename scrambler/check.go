@@ -0,0 +1,83 @@
+package scrambler
+
+import (
+	"crypto/hmac"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// MaxCheckWords is the largest number of check words supported.
+const MaxCheckWords = 4
+
+// checkContext separates the check word MAC from any other use of the key.
+const checkContext = "walletscrambler check words v1"
+
+// CheckWords returns n words that authenticate scrambled under this key.
+// Stored with the scrambled words, they let VerifyCheckWords detect a wrong
+// password or salt on recovery. Every check word also lets an attacker who
+// finds the backup rule out all but one in 2^bits password guesses, so they
+// are optional.
+func (k *Key) CheckWords(scrambled []string, n int) ([]string, error) {
+	if n < 1 || n > MaxCheckWords {
+		return nil, fmt.Errorf("number of check words must be between 1 and %d", MaxCheckWords)
+	}
+	indices, err := k.checkIndices(scrambled, n)
+	if err != nil {
+		return nil, err
+	}
+	wordlist := k.mode.Wordlist()
+	check := make([]string, n)
+	for i, index := range indices {
+		check[i] = wordlist[index]
+	}
+	return check, nil
+}
+
+// VerifyCheckWords returns ErrWrongPassword unless check are the check words
+// of scrambled under this key.
+func (k *Key) VerifyCheckWords(scrambled, check []string) error {
+	if len(check) < 1 || len(check) > MaxCheckWords {
+		return fmt.Errorf("got %d check words, expected between 1 and %d", len(check), MaxCheckWords)
+	}
+	want, err := k.checkIndices(scrambled, len(check))
+	if err != nil {
+		return err
+	}
+	wordlist := k.mode.Wordlist()
+	match := true
+	for i, word := range check {
+		index := wordlist.Index(word)
+		if index < 0 {
+			return unknownWord(wordlist, word, i+1, false)
+		}
+		match = match && uint16(index) == want[i]
+	}
+	if !match {
+		return ErrWrongPassword
+	}
+	return nil
+}
+
+// checkIndices computes a keyed SHA3-256 MAC over the mode and the scrambled
+// word indices and splits its leading bits into n word indices.
+func (k *Key) checkIndices(scrambled []string, n int) ([]uint16, error) {
+	if k.key == nil {
+		return nil, ErrKeyWiped
+	}
+	indices, err := wordIndices(k.mode, scrambled)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha3.New256, k.key.Bytes())
+	mac.Write([]byte(checkContext))
+	mac.Write([]byte{0})
+	mac.Write([]byte(k.mode.Name()))
+	mac.Write([]byte{0})
+	for _, index := range indices {
+		mac.Write([]byte{byte(index >> 8), byte(index)})
+	}
+	sum := mac.Sum(nil)
+	defer zero(sum)
+	return unpackIndices(sum, n, k.mode.Wordlist().BitsPerWord()), nil
+}
@@ -0,0 +1,56 @@
+package scrambler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCheckWords(t *testing.T) {
+	s := New(WithProfile(testProfile))
+	words := strings.Fields("academic acid acne acquire acrobat activity actress adapt adequate adjust admit zero")
+	salt := []string{"acid", "zero"}
+
+	key, err := s.DeriveKey([]byte("password"), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Wipe()
+	scrambled, err := key.Scramble(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check, err := key.CheckWords(scrambled, MaxCheckWords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := key.VerifyCheckWords(scrambled, check); err != nil {
+		t.Errorf("correct password: got %v, want nil", err)
+	}
+	if err := key.VerifyCheckWords(scrambled, check[:1]); err != nil {
+		t.Errorf("first check word only: got %v, want nil", err)
+	}
+	if _, err := key.CheckWords(scrambled, MaxCheckWords+1); err == nil {
+		t.Errorf("%d check words: got nil error", MaxCheckWords+1)
+	}
+
+	tampered := append([]string{}, scrambled...)
+	tampered[0], tampered[1] = tampered[1], tampered[0]
+	if err := key.VerifyCheckWords(tampered, check); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("swapped words: got %v, want ErrWrongPassword", err)
+	}
+
+	wrong, err := s.DeriveKey([]byte("passw0rd"), salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wrong.Wipe()
+	if err := wrong.VerifyCheckWords(scrambled, check); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("wrong password: got %v, want ErrWrongPassword", err)
+	}
+
+	key.Wipe()
+	if err := key.VerifyCheckWords(scrambled, check); !errors.Is(err, ErrKeyWiped) {
+		t.Errorf("wiped key: got %v, want ErrKeyWiped", err)
+	}
+}
@@ -0,0 +1,134 @@
+package scrambler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// MinDuressCheckWords is the fewest check words per slot of a duress backup.
+// A single word would pick the wrong slot for one password in 1024.
+const MinDuressCheckWords = 2
+
+// ErrSamePassword is returned when the duress password is the password of
+// the real wallet.
+var ErrSamePassword = errors.New("duress password must differ from the wallet password")
+
+// DuressBackup is a backup with two slots of scrambled words, each with its
+// own check words. One slot unscrambles to the real wallet under the wallet
+// password, the other to a decoy wallet under a duress password, or holds
+// random words when there is no decoy. Both slots are valid backups of the
+// same length in random order and the check words look random without the
+// key, so the backup does not show whether a duress password exists.
+type DuressBackup struct {
+	Slots [2][]string
+	Check [2][]string
+}
+
+// SealDuress scrambles words under key and decoyWords under decoy into a
+// DuressBackup with checkWords check words per slot. decoy may be nil, in
+// which case the second slot is filled with random words. Both keys must
+// have been derived with the same salt so either password recovers its slot.
+func SealDuress(key *Key, words []string, decoy *Key, decoyWords []string, checkWords int) (*DuressBackup, error) {
+	if checkWords < MinDuressCheckWords || checkWords > MaxCheckWords {
+		return nil, fmt.Errorf("a duress backup needs between %d and %d check words", MinDuressCheckWords, MaxCheckWords)
+	}
+	if key.key == nil {
+		return nil, ErrKeyWiped
+	}
+	var b DuressBackup
+	slot, err := key.Scramble(words)
+	if err != nil {
+		return nil, err
+	}
+	check, err := key.CheckWords(slot, checkWords)
+	if err != nil {
+		return nil, err
+	}
+	b.Slots[0], b.Check[0] = slot, check
+
+	if decoy != nil {
+		if decoy.key == nil {
+			return nil, ErrKeyWiped
+		}
+		if decoy.mode != key.mode {
+			return nil, fmt.Errorf("decoy words are in %s mode, wallet words in %s mode", decoy.mode.Name(), key.mode.Name())
+		}
+		if subtle.ConstantTimeCompare(decoy.key.Bytes(), key.key.Bytes()) == 1 {
+			return nil, ErrSamePassword
+		}
+		if len(decoyWords) != len(words) {
+			return nil, fmt.Errorf("got %d decoy words, expected %d like the wallet", len(decoyWords), len(words))
+		}
+		if slot, err = decoy.Scramble(decoyWords); err != nil {
+			return nil, fmt.Errorf("decoy: %w", err)
+		}
+		if check, err = decoy.CheckWords(slot, checkWords); err != nil {
+			return nil, err
+		}
+	} else if slot, check, err = fillerSlot(key.mode, words, checkWords); err != nil {
+		return nil, err
+	}
+	b.Slots[1], b.Check[1] = slot, check
+
+	var order [1]byte
+	if _, err := rand.Read(order[:]); err != nil {
+		return nil, err
+	}
+	if order[0]&1 == 1 {
+		b.Slots[0], b.Slots[1] = b.Slots[1], b.Slots[0]
+		b.Check[0], b.Check[1] = b.Check[1], b.Check[0]
+	}
+	return &b, nil
+}
+
+// OpenDuress returns the original words of the slot of b whose check words
+// match this key, or ErrWrongPassword if neither does.
+func (k *Key) OpenDuress(b *DuressBackup) ([]string, error) {
+	for i := range b.Slots {
+		err := k.VerifyCheckWords(b.Slots[i], b.Check[i])
+		if err == nil {
+			return k.Unscramble(b.Slots[i])
+		}
+		if !errors.Is(err, ErrWrongPassword) {
+			return nil, fmt.Errorf("slot %d: %w", i+1, err)
+		}
+	}
+	return nil, ErrWrongPassword
+}
+
+// fillerSlot returns a slot that cannot be told apart from a scrambled
+// decoy: words scrambled with a random key, so checksums stay valid, and
+// random check words. A SLIP39 share also gets a random identifier, as a
+// decoy share from another wallet would have one.
+func fillerSlot(mode Mode, words []string, checkWords int) (slot, check []string, err error) {
+	indices, err := wordIndices(mode, words)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zeroIndices(indices)
+	noise := make([]byte, minKeyLen+2+2*MaxCheckWords)
+	defer zero(noise)
+	if _, err := rand.Read(noise); err != nil {
+		return nil, nil, err
+	}
+	key, extra := noise[:minKeyLen], noise[minKeyLen:]
+	if mode == ModeSLIP39 {
+		slip39SetIdentifier(indices, readBits(extra, 0, slip39IdentifierBits))
+		extra = extra[2:]
+	}
+	newIndices := mode.apply(key, indices)
+	defer zeroIndices(newIndices)
+
+	wordlist := mode.Wordlist()
+	slot = make([]string, len(newIndices))
+	for i, index := range newIndices {
+		slot[i] = wordlist[index]
+	}
+	check = make([]string, checkWords)
+	for i, index := range unpackIndices(extra, checkWords, wordlist.BitsPerWord()) {
+		check[i] = wordlist[index]
+	}
+	return slot, check, nil
+}
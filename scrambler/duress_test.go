@@ -0,0 +1,95 @@
+package scrambler
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDuress(t *testing.T) {
+	salt := []string{"acid", "zero"}
+	for _, tc := range []struct{ wallet, decoy string }{
+		{"words-12-salt-0", "words-12-salt-16"},
+		{"bip39-24-salt-4", "bip39-24-salt-4"},
+		{"slip39-20-official", "slip39-20-official"},
+	} {
+		wallet, decoyWallet := vectorByName(t, tc.wallet), vectorByName(t, tc.decoy)
+		mode, _ := ModeByName(wallet.Mode)
+		t.Run(mode.Name(), func(t *testing.T) {
+			s := New(WithMode(mode), WithProfile(testProfile))
+			key, err := s.DeriveKey([]byte("password"), salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer key.Wipe()
+			decoy, err := s.DeriveKey([]byte("duress"), salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer decoy.Wipe()
+
+			backup, err := SealDuress(key, wallet.Words, decoy, decoyWallet.Scrambled, MinDuressCheckWords)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := key.OpenDuress(backup); err != nil || !equalWords(got, wallet.Words) {
+				t.Errorf("wallet password: got %q, %v", got, err)
+			}
+			if got, err := decoy.OpenDuress(backup); err != nil || !equalWords(got, decoyWallet.Scrambled) {
+				t.Errorf("duress password: got %q, %v", got, err)
+			}
+
+			filler, err := SealDuress(key, wallet.Words, nil, nil, MinDuressCheckWords)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, slot := range filler.Slots {
+				if err := s.ValidateWords(slot); err != nil {
+					t.Errorf("random slot %d is not a valid backup: %v", i+1, err)
+				}
+			}
+			if got, err := key.OpenDuress(filler); err != nil || !equalWords(got, wallet.Words) {
+				t.Errorf("wallet password with random slot: got %q, %v", got, err)
+			}
+			if _, err := decoy.OpenDuress(filler); !errors.Is(err, ErrWrongPassword) {
+				t.Errorf("duress password with random slot: got %v, want ErrWrongPassword", err)
+			}
+		})
+	}
+}
+
+func TestDuressErrors(t *testing.T) {
+	s := New(WithProfile(testProfile))
+	words := vectorByName(t, "words-12-salt-0").Words
+	key, err := s.DeriveKey([]byte("password"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Wipe()
+	same, err := s.DeriveKey([]byte("password"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer same.Wipe()
+
+	if _, err := SealDuress(key, words, same, words, MinDuressCheckWords); !errors.Is(err, ErrSamePassword) {
+		t.Errorf("same password: got %v, want ErrSamePassword", err)
+	}
+	if _, err := SealDuress(key, words, nil, nil, MinDuressCheckWords-1); err == nil {
+		t.Errorf("%d check words: got nil error", MinDuressCheckWords-1)
+	}
+}
+
+func vectorByName(t *testing.T, name string) Vector {
+	t.Helper()
+	vectors, err := Vectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		if v.Name == name {
+			return v
+		}
+	}
+	t.Fatalf("no vector %s", name)
+	return Vector{}
+}
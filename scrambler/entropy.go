@@ -0,0 +1,121 @@
+package scrambler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// mixContext separates the salt mixing hash from any other use of SHA3.
+const mixContext = "walletscrambler salt mixing v1"
+
+// ErrRejected is returned by Source.WordIndex for outcomes that fall outside
+// the largest multiple of the wordlist size. Mapping them to a word would
+// make some words more likely than others, so the whole group is thrown
+// away and must be rolled or flipped again.
+var ErrRejected = errors.New("outcomes rejected to keep the words unbiased")
+
+// Source is a physical source of randomness whose outcomes are typed in by
+// hand, such as a die or a coin.
+type Source struct {
+	Name    string // singular noun, as in "roll a die"
+	Symbols string // the faces in order, as they are typed
+}
+
+var (
+	// Die is a six-sided die, entered as the digits 1 to 6.
+	Die = Source{Name: "die", Symbols: "123456"}
+	// Coin is a coin, entered as H for heads and T for tails.
+	Coin = Source{Name: "coin", Symbols: "HT"}
+)
+
+// Sides returns the number of equally likely outcomes of one throw.
+func (s Source) Sides() int {
+	return len(s.Symbols)
+}
+
+// OutcomesPerWord returns how many throws are combined into one word of
+// list: the fewest whose combinations cover every word.
+func (s Source) OutcomesPerWord(list Wordlist) int {
+	n, total := 0, 1
+	for total < len(list) {
+		total *= s.Sides()
+		n++
+	}
+	return n
+}
+
+// Parse returns the face numbers, counted from zero, of the outcomes typed
+// as text. Case, spaces and commas are ignored.
+func (s Source) Parse(text string) ([]int, error) {
+	var outcomes []int
+	for _, r := range strings.ToUpper(text) {
+		if r == ' ' || r == ',' {
+			continue
+		}
+		face := strings.IndexRune(s.Symbols, r)
+		if face < 0 {
+			return nil, fmt.Errorf("%q is not a side of a %s, use %s", r, s.Name, strings.Join(strings.Split(s.Symbols, ""), ", "))
+		}
+		outcomes = append(outcomes, face)
+	}
+	return outcomes, nil
+}
+
+// WordIndex combines OutcomesPerWord outcomes into the index of a word of
+// list by reading them as the digits of a number. Numbers beyond the last
+// whole multiple of the list size are rejected with ErrRejected rather than
+// wrapped around, so every word is equally likely.
+func (s Source) WordIndex(list Wordlist, outcomes []int) (int, error) {
+	if want := s.OutcomesPerWord(list); len(outcomes) != want {
+		return 0, fmt.Errorf("a word takes %d throws of a %s, got %d", want, s.Name, len(outcomes))
+	}
+	value, total := 0, 1
+	for _, face := range outcomes {
+		if face < 0 || face >= s.Sides() {
+			return 0, fmt.Errorf("a %s has no side %d", s.Name, face+1)
+		}
+		value = value*s.Sides() + face
+		total *= s.Sides()
+	}
+	if value >= total-total%len(list) {
+		return 0, ErrRejected
+	}
+	return value % len(list), nil
+}
+
+// MixSalt returns one word of list for every index, drawn from SHAKE256 over
+// the indices and seed. With seed taken from the machine's random generator
+// the words are as unpredictable as the better of the two sources: neither
+// a flawed generator nor a loaded die alone can bias them.
+func MixSalt(list Wordlist, indices []int, seed []byte) []string {
+	h := sha3.NewShake256()
+	h.Write([]byte(mixContext))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(len(indices)))
+	h.Write(buf[:])
+	for _, index := range indices {
+		binary.BigEndian.PutUint16(buf[:2], uint16(index))
+		h.Write(buf[:2])
+	}
+	h.Write(seed)
+	limit := 1 << 16
+	limit -= limit % len(list)
+	words := make([]string, 0, len(indices))
+	for len(words) < len(indices) {
+		h.Read(buf[:2])
+		if value := int(binary.BigEndian.Uint16(buf[:2])); value < limit {
+			words = append(words, list[value%len(list)])
+		}
+	}
+	return words
+}
+
+// SaltEntropy returns the number of bits of entropy count salt words drawn
+// uniformly from list contribute.
+func SaltEntropy(list Wordlist, count int) int {
+	return count * list.BitsPerWord()
+}
@@ -0,0 +1,127 @@
+package scrambler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOutcomesPerWord(t *testing.T) {
+	for _, tt := range []struct {
+		source Source
+		list   Wordlist
+		want   int
+	}{
+		{Die, SLIP39, 4},
+		{Die, BIP39, 5},
+		{Coin, SLIP39, 10},
+		{Coin, BIP39, 11},
+	} {
+		if got := tt.source.OutcomesPerWord(tt.list); got != tt.want {
+			t.Errorf("%s.OutcomesPerWord(%d words) = %d, want %d", tt.source.Name, len(tt.list), got, tt.want)
+		}
+	}
+}
+
+func TestWordIndex(t *testing.T) {
+	for _, tt := range []struct {
+		source Source
+		list   Wordlist
+		typed  string
+		want   int
+		err    error
+	}{
+		{Die, SLIP39, "1111", 0, nil},
+		{Die, SLIP39, "1 2 3 4", 0*216 + 1*36 + 2*6 + 3, nil},
+		{Die, SLIP39, "5,4,2,6", 4*216 + 3*36 + 1*6 + 5, nil},
+		{Die, SLIP39, "5551", 0, ErrRejected}, // 1032 of 1296
+		{Die, SLIP39, "6666", 0, ErrRejected},
+		{Die, BIP39, "55346", 2047, nil},      // 6143, the last value kept
+		{Die, BIP39, "61111", 0, ErrRejected}, // 6480 of 7776
+		{Coin, SLIP39, "hhhhhhhhht", 1, nil},
+		{Coin, BIP39, "TTTTTTTTTTT", 2047, nil},
+	} {
+		outcomes, err := tt.source.Parse(tt.typed)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.typed, err)
+		}
+		got, err := tt.source.WordIndex(tt.list, outcomes)
+		if !errors.Is(err, tt.err) || err == nil && got != tt.want {
+			t.Errorf("%s.WordIndex(%q) = %d, %v, want %d, %v", tt.source.Name, tt.typed, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestWordIndexUnbiased(t *testing.T) {
+	for _, source := range []Source{Die, Coin} {
+		for _, list := range []Wordlist{SLIP39, BIP39} {
+			n := source.OutcomesPerWord(list)
+			counts := make([]int, len(list))
+			outcomes := make([]int, n)
+			for {
+				if index, err := source.WordIndex(list, outcomes); err == nil {
+					counts[index]++
+				} else if !errors.Is(err, ErrRejected) {
+					t.Fatal(err)
+				}
+				i := n - 1
+				for i >= 0 && outcomes[i] == source.Sides()-1 {
+					outcomes[i] = 0
+					i--
+				}
+				if i < 0 {
+					break
+				}
+				outcomes[i]++
+			}
+			for index, count := range counts {
+				if count != counts[0] {
+					t.Fatalf("%s with %d words: word %d drawn %d times, word 0 %d times", source.Name, len(list), index, count, counts[0])
+				}
+			}
+		}
+	}
+}
+
+func TestWordIndexInvalid(t *testing.T) {
+	if _, err := Die.Parse("1237"); err == nil {
+		t.Error("Parse accepted 7 as a die roll")
+	}
+	if _, err := Coin.Parse("HTX"); err == nil {
+		t.Error("Parse accepted X as a coin flip")
+	}
+	if _, err := Die.WordIndex(SLIP39, []int{0, 1, 2}); err == nil {
+		t.Error("WordIndex accepted three rolls for a SLIP39 word")
+	}
+}
+
+func TestMixSalt(t *testing.T) {
+	indices := []int{1, 2, 3, 4}
+	a := MixSalt(SLIP39, indices, []byte("seed a"))
+	if len(a) != len(indices) {
+		t.Fatalf("MixSalt returned %d words, want %d", len(a), len(indices))
+	}
+	for _, word := range a {
+		if !SLIP39.Contains(word) {
+			t.Errorf("MixSalt returned %q, which is not a SLIP39 word", word)
+		}
+	}
+	if again := MixSalt(SLIP39, indices, []byte("seed a")); !reflect.DeepEqual(a, again) {
+		t.Errorf("MixSalt is not deterministic: %q and %q", a, again)
+	}
+	if b := MixSalt(SLIP39, indices, []byte("seed b")); reflect.DeepEqual(a, b) {
+		t.Errorf("MixSalt ignores the seed: %q", a)
+	}
+	if c := MixSalt(SLIP39, []int{1, 2, 3, 5}, []byte("seed a")); reflect.DeepEqual(a, c) {
+		t.Errorf("MixSalt ignores the indices: %q", a)
+	}
+}
+
+func TestSaltEntropy(t *testing.T) {
+	if got := SaltEntropy(SLIP39, 4); got != 40 {
+		t.Errorf("SaltEntropy(SLIP39, 4) = %d, want 40", got)
+	}
+	if got := SaltEntropy(BIP39, 3); got != 33 {
+		t.Errorf("SaltEntropy(BIP39, 3) = %d, want 33", got)
+	}
+}
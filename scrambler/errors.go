@@ -0,0 +1,88 @@
+package scrambler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksum is returned when the checksum embedded in the wallet words does
+// not match, which usually means a word was mistyped.
+var ErrChecksum = errors.New("invalid checksum")
+
+// ErrPadding is returned when the padding bits of a SLIP39 share are not
+// zero.
+var ErrPadding = errors.New("invalid share padding")
+
+// ErrWrongPassword is returned when check words do not match, which means
+// the password or salt used to unscramble is not the one used to scramble.
+var ErrWrongPassword = errors.New("password or salt incorrect")
+
+// ErrKeyWiped is returned when a Key is used after Wipe.
+var ErrKeyWiped = errors.New("key has been wiped")
+
+// UnknownWordError is returned when a wallet or salt word is not part of the
+// wordlist.
+type UnknownWordError struct {
+	Word        string
+	Position    int // 1-based position of the word in its list
+	Salt        bool
+	Suggestions []string // words of the list the word may be a typo of
+	Other       string   // name of the standard wordlist the word is from, if any
+}
+
+// unknownWord returns an UnknownWordError for word with suggestions from
+// list.
+func unknownWord(list Wordlist, word string, position int, salt bool) *UnknownWordError {
+	e := &UnknownWordError{Word: word, Position: position, Salt: salt}
+	if other, ok := list.OtherWordlist(word); ok {
+		e.Other = other
+	} else {
+		e.Suggestions = list.Suggest(word)
+	}
+	return e
+}
+
+func (e *UnknownWordError) Error() string {
+	kind := "word"
+	if e.Salt {
+		kind = "salt word"
+	}
+	msg := fmt.Sprintf("%s %d (%q) is not in the wordlist", kind, e.Position, e.Word)
+	switch {
+	case e.Other != "":
+		msg += fmt.Sprintf(", it is a %s word", e.Other)
+	case len(e.Suggestions) > 0:
+		quoted := make([]string, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			quoted[i] = strconv.Quote(s)
+		}
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(quoted, " or "))
+	}
+	return msg
+}
+
+// LengthError is returned when the number of wallet or salt words is out of
+// range.
+type LengthError struct {
+	Count    int
+	Min, Max int
+	Valid    []int // the only valid counts, when not a plain range
+	Salt     bool
+}
+
+func (e *LengthError) Error() string {
+	kind := "wallet words"
+	if e.Salt {
+		kind = "salt words"
+	}
+	if len(e.Valid) > 0 {
+		valid := make([]string, len(e.Valid))
+		for i, n := range e.Valid {
+			valid[i] = strconv.Itoa(n)
+		}
+		return fmt.Sprintf("got %d %s, expected one of %s", e.Count, kind, strings.Join(valid, ", "))
+	}
+	return fmt.Sprintf("got %d %s, expected between %d and %d", e.Count, kind, e.Min, e.Max)
+}
@@ -0,0 +1,273 @@
+package scrambler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+
+	"walletscrambler/internal/secmem"
+)
+
+// emptySalt replaces the salt when no salt words are given.
+const emptySalt = "I was too lazy to enter a salt"
+
+// minKeyLen is the smallest key, in bytes, that covers the scrambled bits
+// of the longest backup in any mode.
+const minKeyLen = (MaxWords*10 + 7) / 8
+
+// KDFProfile is a versioned set of key derivation parameters. A backup can
+// only be recovered with the profile it was scrambled with, so registered
+// profiles must never change.
+type KDFProfile struct {
+	ID            string
+	SHA3Rounds    int    // iterations of the SHA3-256 chain over the salt
+	Argon2Time    uint32 // Argon2id passes
+	Argon2Memory  uint32 // Argon2id memory in KiB
+	Argon2Threads uint8
+	KeyLen        uint32 // bytes of key material
+}
+
+func (p KDFProfile) validate() error {
+	switch {
+	case p.ID == "":
+		return fmt.Errorf("KDF profile has no ID")
+	case p.SHA3Rounds < 1 || p.Argon2Time < 1 || p.Argon2Threads < 1:
+		return fmt.Errorf("KDF profile %q: rounds, time and threads must be positive", p.ID)
+	case p.Argon2Memory < 8*uint32(p.Argon2Threads):
+		return fmt.Errorf("KDF profile %q: Argon2 memory must be at least 8 KiB per thread", p.ID)
+	case p.KeyLen < minKeyLen:
+		return fmt.Errorf("KDF profile %q: key length must be at least %d bytes", p.ID, minKeyLen)
+	}
+	return nil
+}
+
+// MemoryRequired returns the memory, in bytes, that Argon2id allocates for
+// the profile.
+func (p KDFProfile) MemoryRequired() uint64 {
+	return uint64(p.Argon2Memory) * 1024
+}
+
+// DefaultProfile is the ID of the profile used for new scrambles.
+const DefaultProfile = "v1"
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]KDFProfile{
+		// v1 is the original parameter set. It must never change.
+		"v1": {
+			ID:            "v1",
+			SHA3Rounds:    4847868,
+			Argon2Time:    64,
+			Argon2Memory:  1024 * 1024,
+			Argon2Threads: 4,
+			KeyLen:        64,
+		},
+		// lowmem-v1 is for machines with less than 2 GiB of memory. It does
+		// the same Argon2id work as v1 in a quarter of the memory, which
+		// makes it cheaper to attack with dedicated hardware. It must never
+		// change.
+		"lowmem-v1": {
+			ID:            "lowmem-v1",
+			SHA3Rounds:    4847868,
+			Argon2Time:    256,
+			Argon2Memory:  256 * 1024,
+			Argon2Threads: 4,
+			KeyLen:        64,
+		},
+	}
+)
+
+// RegisterProfile adds p to the profile registry. Existing profiles cannot
+// be replaced and IDs starting with CustomPrefix are reserved.
+func RegisterProfile(p KDFProfile) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if strings.HasPrefix(p.ID, CustomPrefix) {
+		return fmt.Errorf("KDF profile %q: IDs starting with %q are reserved", p.ID, CustomPrefix)
+	}
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	if _, exists := profiles[p.ID]; exists {
+		return fmt.Errorf("KDF profile %q is already registered", p.ID)
+	}
+	profiles[p.ID] = p
+	return nil
+}
+
+// LookupProfile returns the registered profile with the given ID, or the
+// custom profile the ID describes.
+func LookupProfile(id string) (KDFProfile, bool) {
+	profilesMu.RLock()
+	p, ok := profiles[id]
+	profilesMu.RUnlock()
+	if ok {
+		return p, true
+	}
+	return parseCustomProfile(id)
+}
+
+// Profiles returns every registered profile sorted by ID. Custom profiles
+// are not included.
+func Profiles() []KDFProfile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	list := make([]KDFProfile, 0, len(profiles))
+	for _, p := range profiles {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Phase is a stage of the key derivation.
+type Phase int
+
+const (
+	PhaseSHA3   Phase = iota // the SHA3-256 chain over the salt
+	PhaseArgon2              // the Argon2id call
+	PhaseDone                // the key is ready
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseSHA3:
+		return "sha3"
+	case PhaseArgon2:
+		return "argon2"
+	case PhaseDone:
+		return "done"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// Progress reports how far the key derivation has come.
+type Progress struct {
+	Phase   Phase
+	Done    int           // SHA3 rounds completed
+	Total   int           // SHA3 rounds in total
+	Elapsed time.Duration // time spent in the phase, or in total when done
+}
+
+// ETA estimates the time left in the SHA3 phase, or returns zero when there
+// is no estimate. Argon2 gives no progress, so its duration is unknown.
+func (p Progress) ETA() time.Duration {
+	if p.Phase != PhaseSHA3 || p.Done == 0 {
+		return 0
+	}
+	return time.Duration(float64(p.Elapsed) * float64(p.Total-p.Done) / float64(p.Done))
+}
+
+const (
+	// progressRounds is how many SHA3 rounds pass between reports.
+	progressRounds = 1 << 16
+	// progressInterval is how often the Argon2 phase is reported.
+	progressInterval = 500 * time.Millisecond
+)
+
+// hashRepeatedly applies SHA3-256 to data iterations times, calling report,
+// if not nil, with the number of rounds done every progressRounds rounds.
+// It stops early with the context's error when ctx is cancelled. The chain
+// is computed in a single array so no intermediate digest is left on the
+// heap.
+func hashRepeatedly(ctx context.Context, data []byte, iterations int, report func(done int)) ([]byte, error) {
+	var digest [32]byte
+	hash := data
+	for i := 0; i < iterations; i++ {
+		digest = sha3.Sum256(hash)
+		hash = digest[:]
+		if (i+1)%progressRounds == 0 {
+			if err := ctx.Err(); err != nil {
+				zero(digest[:])
+				return nil, err
+			}
+			if report != nil {
+				report(i + 1)
+			}
+		}
+	}
+	return hash, nil
+}
+
+// deriveKey stretches the salt with a SHA3 chain and feeds the result to
+// Argon2id together with the password. The key is returned in locked memory
+// and every intermediate value is zeroed. report, if not nil, is called with
+// the progress of each phase; calls never overlap.
+//
+// Cancelling ctx stops the SHA3 chain. Argon2id cannot be interrupted, so a
+// cancellation during it is noticed when it returns and its output is
+// wiped.
+func deriveKey(ctx context.Context, profile KDFProfile, password []byte, saltWords []string, report func(Progress)) (*secmem.Buffer, error) {
+	if report == nil {
+		report = func(Progress) {}
+	}
+	salt := strings.Join(saltWords, "")
+	if salt == "" {
+		salt = emptySalt
+	}
+	start := time.Now()
+	report(Progress{Phase: PhaseSHA3, Total: profile.SHA3Rounds})
+	argon2Seed, err := hashRepeatedly(ctx, []byte(salt), profile.SHA3Rounds, func(done int) {
+		report(Progress{Phase: PhaseSHA3, Done: done, Total: profile.SHA3Rounds, Elapsed: time.Since(start)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer zero(argon2Seed)
+	report(Progress{Phase: PhaseSHA3, Done: profile.SHA3Rounds, Total: profile.SHA3Rounds, Elapsed: time.Since(start)})
+
+	argon2Start := time.Now()
+	report(Progress{Phase: PhaseArgon2, Done: profile.SHA3Rounds, Total: profile.SHA3Rounds})
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				report(Progress{Phase: PhaseArgon2, Done: profile.SHA3Rounds, Total: profile.SHA3Rounds, Elapsed: time.Since(argon2Start)})
+			}
+		}
+	}()
+	argon2Hash := argon2.IDKey(password, argon2Seed, profile.Argon2Time, profile.Argon2Memory, profile.Argon2Threads, profile.KeyLen)
+	defer zero(argon2Hash)
+	close(stop)
+	<-stopped
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	report(Progress{Phase: PhaseDone, Done: profile.SHA3Rounds, Total: profile.SHA3Rounds, Elapsed: time.Since(start)})
+
+	key := secmem.New(len(argon2Hash))
+	key.Write(argon2Hash)
+	return key, nil
+}
+
+// walletContext prefixes the HKDF info string of a labelled wallet key.
+const walletContext = "walletscrambler wallet v1\x00"
+
+// walletKey expands key with HKDF-SHA3-256 into an independent key of the
+// same length for the wallet called label. key is already uniformly random,
+// so the HKDF extract step is skipped.
+func walletKey(key []byte, label string) (*secmem.Buffer, error) {
+	expanded := make([]byte, len(key))
+	defer zero(expanded)
+	r := hkdf.Expand(sha3.New256, key, []byte(walletContext+label))
+	if _, err := io.ReadFull(r, expanded); err != nil {
+		return nil, err
+	}
+	buf := secmem.New(len(expanded))
+	buf.Write(expanded)
+	return buf, nil
+}
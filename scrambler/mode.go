@@ -0,0 +1,93 @@
+package scrambler
+
+// Mode determines which wordlist wallet words come from and which of their
+// bits are scrambled.
+type Mode interface {
+	// Name identifies the mode on the command line and in output.
+	Name() string
+	// Wordlist returns the list wallet and salt words are taken from.
+	Wordlist() Wordlist
+	// WordCounts lists the valid numbers of wallet words in ascending order.
+	WordCounts() []int
+	// CheckWordCount returns a *LengthError if n wallet words are not valid
+	// in this mode.
+	CheckWordCount(n int) error
+
+	// validate checks the word indices beyond their count and membership
+	// of the wordlist, e.g. an embedded checksum.
+	validate(indices []uint16) error
+	// apply XORs the scrambled part of indices with key and returns the new
+	// indices. indices have already been validated.
+	apply(key []byte, indices []uint16) []uint16
+}
+
+var (
+	// ModeWords scrambles every word of a SLIP39 wordlist backup
+	// independently. It is the original mode and does not preserve any
+	// checksum.
+	ModeWords Mode = wordsMode{}
+
+	// ModeBIP39 scrambles the entropy of a BIP39 mnemonic and recomputes
+	// its checksum word, so the scrambled words are a valid mnemonic too.
+	ModeBIP39 Mode = bip39Mode{}
+
+	// ModeSLIP39 scrambles the share value of a SLIP39 share and recomputes
+	// its RS1024 checksum, leaving the share metadata untouched.
+	ModeSLIP39 Mode = slip39Mode{}
+)
+
+// Modes lists every supported mode.
+var Modes = []Mode{ModeWords, ModeBIP39, ModeSLIP39}
+
+// ModeByName returns the mode called name.
+func ModeByName(name string) (Mode, bool) {
+	for _, mode := range Modes {
+		if mode.Name() == name {
+			return mode, true
+		}
+	}
+	return nil, false
+}
+
+type wordsMode struct{}
+
+func (wordsMode) Name() string       { return "words" }
+func (wordsMode) Wordlist() Wordlist { return SLIP39 }
+
+func (wordsMode) WordCounts() []int {
+	counts := make([]int, 0, MaxWords-MinWords+1)
+	for n := MinWords; n <= MaxWords; n++ {
+		counts = append(counts, n)
+	}
+	return counts
+}
+
+func (wordsMode) CheckWordCount(n int) error {
+	if n < MinWords || n > MaxWords {
+		return &LengthError{Count: n, Min: MinWords, Max: MaxWords}
+	}
+	return nil
+}
+
+func (wordsMode) validate(indices []uint16) error {
+	return nil
+}
+
+func (wordsMode) apply(key []byte, indices []uint16) []uint16 {
+	wordBitSize := SLIP39.BitsPerWord()
+	newIndices := make([]uint16, len(indices))
+	for i, index := range indices {
+		newIndices[i] = index ^ readBits(key, i*wordBitSize, wordBitSize)
+	}
+	return newIndices
+}
+
+// checkWordCount returns a *LengthError unless n is one of valid.
+func checkWordCount(n int, valid []int) error {
+	for _, count := range valid {
+		if n == count {
+			return nil
+		}
+	}
+	return &LengthError{Count: n, Valid: valid}
+}
@@ -0,0 +1,316 @@
+// Package scrambler transforms wallet backup words into a new set of words
+// using a key derived from a password and optional salt words.
+//
+// The key is derived by stretching the salt with a long SHA3-256 chain and
+// passing the result to Argon2id, with parameters taken from a versioned
+// KDFProfile. A wallet label, when given, expands the result into an
+// independent key per wallet with HKDF-SHA3-256. The bits of the wallet
+// words selected by the Mode are then XORed with the key, so scrambling and
+// unscrambling are the same operation.
+package scrambler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"walletscrambler/internal/secmem"
+)
+
+const (
+	// MinWords and MaxWords bound the number of wallet words.
+	MinWords = 12
+	MaxWords = 33
+
+	// MaxSaltWords is the largest number of salt words accepted.
+	MaxSaltWords = 16
+)
+
+// Scrambler scrambles and unscrambles wallet words.
+type Scrambler struct {
+	mode     Mode
+	profile  KDFProfile
+	label    string
+	progress func(Progress)
+}
+
+// Option configures a Scrambler.
+type Option func(*Scrambler)
+
+// WithMode selects how wallet words are interpreted. The default is
+// ModeWords.
+func WithMode(mode Mode) Option {
+	return func(s *Scrambler) {
+		s.mode = mode
+	}
+}
+
+// WithProfile selects the key derivation parameters. The default is the
+// profile named by DefaultProfile.
+func WithProfile(profile KDFProfile) Option {
+	return func(s *Scrambler) {
+		s.profile = profile
+	}
+}
+
+// WithLabel gives the wallet a label, such as a name or an index, that is
+// mixed into the key. Wallets scrambled with the same password and salt but
+// different labels use independent keys; without labels they share one, and
+// XORing their scrambled words reveals the XOR of the wallets. The default
+// is no label, which gives the key of backups made before labels existed.
+func WithLabel(label string) Option {
+	return func(s *Scrambler) {
+		s.label = label
+	}
+}
+
+// WithProgress sets a function that is called with the progress of every
+// key derivation, from the SHA3 chain every few thousand rounds and from the
+// Argon2id call twice a second. It is called from more than one goroutine,
+// but never concurrently.
+func WithProgress(report func(Progress)) Option {
+	return func(s *Scrambler) {
+		s.progress = report
+	}
+}
+
+// New returns a Scrambler configured by opts.
+func New(opts ...Option) *Scrambler {
+	profile, _ := LookupProfile(DefaultProfile)
+	s := &Scrambler{mode: ModeWords, profile: profile}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Mode returns the mode wallet words are interpreted in.
+func (s *Scrambler) Mode() Mode {
+	return s.mode
+}
+
+// Profile returns the key derivation parameters.
+func (s *Scrambler) Profile() KDFProfile {
+	return s.profile
+}
+
+// Label returns the wallet label mixed into the key.
+func (s *Scrambler) Label() string {
+	return s.label
+}
+
+// Wordlist returns the wordlist used for wallet and salt words.
+func (s *Scrambler) Wordlist() Wordlist {
+	return s.mode.Wordlist()
+}
+
+// Scramble derives the key from password and salt and returns the scrambled
+// wallet words.
+func (s *Scrambler) Scramble(words []string, password []byte, salt []string) ([]string, error) {
+	if err := validateWords(s.mode, words); err != nil {
+		return nil, err
+	}
+	key, err := s.DeriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Wipe()
+	return key.Scramble(words)
+}
+
+// Unscramble derives the key from password and salt and returns the
+// original wallet words.
+func (s *Scrambler) Unscramble(words []string, password []byte, salt []string) ([]string, error) {
+	if err := validateWords(s.mode, words); err != nil {
+		return nil, err
+	}
+	key, err := s.DeriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Wipe()
+	return key.Unscramble(words)
+}
+
+// ValidateWords checks wallet words without deriving a key, so input errors
+// can be reported before the slow key derivation.
+func (s *Scrambler) ValidateWords(words []string) error {
+	return validateWords(s.mode, words)
+}
+
+// DeriveKey runs the key derivation for password and salt. This is the slow
+// part of scrambling; the returned Key can be applied to words afterwards
+// and should be wiped once it is no longer needed.
+func (s *Scrambler) DeriveKey(password []byte, salt []string) (*Key, error) {
+	return s.DeriveKeyContext(context.Background(), password, salt)
+}
+
+// DeriveKeyContext is like DeriveKey but gives up with the context's error
+// when ctx is cancelled. The SHA3 chain stops promptly; the Argon2id call
+// cannot be interrupted, so cancelling during it only takes effect, and
+// wipes its output, once it returns.
+func (s *Scrambler) DeriveKeyContext(ctx context.Context, password []byte, salt []string) (*Key, error) {
+	if err := s.validateSalt(salt); err != nil {
+		return nil, err
+	}
+	if err := s.profile.validate(); err != nil {
+		return nil, err
+	}
+	buf, err := deriveKey(ctx, s.profile, password, salt, s.progress)
+	if err != nil {
+		return nil, err
+	}
+	key := &Key{mode: s.mode, profile: s.profile, key: buf}
+	if s.label == "" {
+		return key, nil
+	}
+	defer key.Wipe()
+	return key.ForWallet(s.label)
+}
+
+func (s *Scrambler) validateSalt(salt []string) error {
+	if len(salt) > MaxSaltWords {
+		return &LengthError{Count: len(salt), Min: 0, Max: MaxSaltWords, Salt: true}
+	}
+	wordlist := s.Wordlist()
+	for i, word := range salt {
+		if !wordlist.Contains(word) {
+			return unknownWord(wordlist, word, i+1, true)
+		}
+	}
+	return nil
+}
+
+func validateWords(mode Mode, words []string) error {
+	indices, err := wordIndices(mode, words)
+	zeroIndices(indices)
+	return err
+}
+
+// wordIndices validates words for mode and returns their positions in the
+// mode's wordlist. The caller should zero the result after use.
+func wordIndices(mode Mode, words []string) ([]uint16, error) {
+	if err := mode.CheckWordCount(len(words)); err != nil {
+		return nil, err
+	}
+	wordlist := mode.Wordlist()
+	indices := make([]uint16, len(words))
+	for i, word := range words {
+		index := wordlist.Index(word)
+		if index < 0 {
+			zeroIndices(indices)
+			return nil, unknownWord(wordlist, word, i+1, false)
+		}
+		indices[i] = uint16(index)
+	}
+	if err := mode.validate(indices); err != nil {
+		zeroIndices(indices)
+		return nil, err
+	}
+	return indices, nil
+}
+
+// Key is derived key material, held in locked memory.
+type Key struct {
+	mode    Mode
+	profile KDFProfile
+	label   string
+	key     *secmem.Buffer
+}
+
+// Profile returns the parameters the key was derived with.
+func (k *Key) Profile() KDFProfile {
+	return k.profile
+}
+
+// Label returns the wallet label of the key, if any.
+func (k *Key) Label() string {
+	return k.label
+}
+
+// ForWallet returns the key of the wallet called label, as a Scrambler with
+// WithLabel(label) would derive it. Deriving the key once and calling
+// ForWallet for each wallet avoids repeating the slow key derivation. The
+// receiver must not have a label itself.
+func (k *Key) ForWallet(label string) (*Key, error) {
+	if k.key == nil {
+		return nil, ErrKeyWiped
+	}
+	if k.label != "" {
+		return nil, fmt.Errorf("key already belongs to wallet %q", k.label)
+	}
+	if label == "" {
+		return nil, errors.New("empty wallet label")
+	}
+	key, err := walletKey(k.key.Bytes(), label)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{mode: k.mode, profile: k.profile, label: label, key: key}, nil
+}
+
+// Scramble returns the scrambled form of words.
+func (k *Key) Scramble(words []string) ([]string, error) {
+	return k.apply(words)
+}
+
+// Unscramble returns the original form of scrambled words.
+func (k *Key) Unscramble(words []string) ([]string, error) {
+	return k.apply(words)
+}
+
+// Rekey returns words scrambled under from scrambled under to instead. The
+// original words are only held as word indices, which are zeroed before
+// Rekey returns, so a password can be changed without revealing the wallet.
+func Rekey(from, to *Key, words []string) ([]string, error) {
+	if from.key == nil || to.key == nil {
+		return nil, ErrKeyWiped
+	}
+	if from.mode != to.mode {
+		return nil, fmt.Errorf("cannot rekey from %s mode to %s mode", from.mode.Name(), to.mode.Name())
+	}
+	indices, err := wordIndices(from.mode, words)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroIndices(indices)
+	original := from.mode.apply(from.key.Bytes(), indices)
+	defer zeroIndices(original)
+	newIndices := to.mode.apply(to.key.Bytes(), original)
+	defer zeroIndices(newIndices)
+
+	wordlist := to.mode.Wordlist()
+	newWords := make([]string, len(newIndices))
+	for i, index := range newIndices {
+		newWords[i] = wordlist[index]
+	}
+	return newWords, nil
+}
+
+// Wipe zeroes the key material. The key cannot be used afterwards.
+func (k *Key) Wipe() {
+	if k.key != nil {
+		k.key.Destroy()
+		k.key = nil
+	}
+}
+
+func (k *Key) apply(words []string) ([]string, error) {
+	if k.key == nil {
+		return nil, ErrKeyWiped
+	}
+	indices, err := wordIndices(k.mode, words)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroIndices(indices)
+	newIndices := k.mode.apply(k.key.Bytes(), indices)
+	defer zeroIndices(newIndices)
+
+	wordlist := k.mode.Wordlist()
+	newWords := make([]string, len(newIndices))
+	for i, index := range newIndices {
+		newWords[i] = wordlist[index]
+	}
+	return newWords, nil
+}
@@ -0,0 +1,118 @@
+package scrambler
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRekey(t *testing.T) {
+	for _, name := range []string{"words-24-salt-0", "bip39-18-salt-2", "slip39-33-official"} {
+		v := vectorByName(t, name)
+		mode, _ := ModeByName(v.Mode)
+		t.Run(name, func(t *testing.T) {
+			s := New(WithMode(mode), WithProfile(testProfile))
+			from, err := s.DeriveKey([]byte(v.Password), v.Salt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer from.Wipe()
+			to, err := s.DeriveKey([]byte("new password"), []string{"acid"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer to.Wipe()
+
+			rekeyed, err := Rekey(from, to, v.Scrambled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := to.Scramble(v.Words)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalWords(rekeyed, want) {
+				t.Errorf("rekeyed to %q, want %q", rekeyed, want)
+			}
+
+			to.Wipe()
+			if _, err := Rekey(from, to, v.Scrambled); !errors.Is(err, ErrKeyWiped) {
+				t.Errorf("wiped key: got %v, want ErrKeyWiped", err)
+			}
+		})
+	}
+}
+
+func TestForWallet(t *testing.T) {
+	v := vectorByName(t, "words-12-label")
+	key, err := New(WithProfile(testProfile)).DeriveKey([]byte(v.Password), v.Salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Wipe()
+
+	wallet, err := key.ForWallet(v.Label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wallet.Wipe()
+	if scrambled, err := wallet.Scramble(v.Words); err != nil || !equalWords(scrambled, v.Scrambled) {
+		t.Errorf("ForWallet(%q) scrambled to %q, %v, want %q", v.Label, scrambled, err, v.Scrambled)
+	}
+	if _, err := wallet.ForWallet("other"); err == nil {
+		t.Error("ForWallet on a labelled key: got nil error")
+	}
+	if _, err := key.ForWallet(""); err == nil {
+		t.Error("ForWallet with an empty label: got nil error")
+	}
+
+	other, err := key.ForWallet(v.Label + " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Wipe()
+	if scrambled, _ := other.Scramble(v.Words); equalWords(scrambled, v.Scrambled) {
+		t.Error("different labels gave the same key stream")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var reports []Progress
+	s := New(WithProfile(testProfile), WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	key, err := s.DeriveKey([]byte("password"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.Wipe()
+
+	if len(reports) == 0 || reports[len(reports)-1].Phase != PhaseDone {
+		t.Fatalf("got %v, want reports ending in PhaseDone", reports)
+	}
+	phase := PhaseSHA3
+	for _, p := range reports {
+		if p.Phase < phase {
+			t.Errorf("phase went back from %v to %v", phase, p.Phase)
+		}
+		if p.Phase > PhaseSHA3 && p.Done != testProfile.SHA3Rounds {
+			t.Errorf("%v reported after %d of %d SHA3 rounds", p.Phase, p.Done, testProfile.SHA3Rounds)
+		}
+		phase = p.Phase
+	}
+}
+
+func TestDeriveKeyContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	profile := testProfile
+	profile.SHA3Rounds = 4 * progressRounds
+	s := New(WithProfile(profile))
+	if key, err := s.DeriveKeyContext(ctx, []byte("password"), nil); !errors.Is(err, context.Canceled) || key != nil {
+		t.Errorf("cancelled during SHA3: got %v, %v, want context.Canceled", key, err)
+	}
+	s = New(WithProfile(testProfile))
+	if key, err := s.DeriveKeyContext(ctx, []byte("password"), nil); !errors.Is(err, context.Canceled) || key != nil {
+		t.Errorf("cancelled during Argon2id: got %v, %v, want context.Canceled", key, err)
+	}
+}
@@ -0,0 +1,115 @@
+package scrambler
+
+// A SLIP39 share is made of two words of identifier, extendable flag and
+// iteration exponent, two words of group and member parameters, the padded
+// share value and three RS1024 checksum words.
+const (
+	slip39MetadataWords  = 4
+	slip39ChecksumWords  = 3
+	slip39IdentifierBits = 15
+)
+
+// slip39WordCounts lists the share lengths whose share value is a multiple
+// of 16 bits of at least 128 bits with at most 8 bits of padding.
+var slip39WordCounts = []int{20, 22, 23, 25, 27, 28, 30, 31, 33}
+
+// rs1024Generator holds the generator coefficients of the RS1024 checksum.
+var rs1024Generator = [10]uint32{
+	0xE0E040, 0x1C1C080, 0x3838100, 0x7070200, 0xE0E0009,
+	0x1C0C2412, 0x38086C24, 0x3090FC48, 0x21B1F890, 0x3F3F120,
+}
+
+type slip39Mode struct{}
+
+func (slip39Mode) Name() string       { return "slip39" }
+func (slip39Mode) Wordlist() Wordlist { return SLIP39 }
+func (slip39Mode) WordCounts() []int  { return slip39WordCounts }
+
+func (slip39Mode) CheckWordCount(n int) error {
+	return checkWordCount(n, slip39WordCounts)
+}
+
+func (slip39Mode) validate(indices []uint16) error {
+	if rs1024Polymod(slip39Customization(indices), indices) != 1 {
+		return ErrChecksum
+	}
+	data, paddingLen := slip39Value(indices)
+	defer zero(data)
+	if readBits(data, 0, paddingLen) != 0 {
+		return ErrPadding
+	}
+	return nil
+}
+
+// apply XORs the share value only and appends a freshly computed checksum.
+func (slip39Mode) apply(key []byte, indices []uint16) []uint16 {
+	data, paddingLen := slip39Value(indices)
+	defer zero(data)
+	wordBitSize := SLIP39.BitsPerWord()
+	valueWords := len(indices) - slip39MetadataWords - slip39ChecksumWords
+	valueLen := valueWords*wordBitSize - paddingLen
+	for i := 0; i < valueLen/8; i++ {
+		offset := paddingLen + i*8
+		writeBits(data, offset, 8, readBits(data, offset, 8)^uint16(key[i]))
+	}
+
+	newIndices := make([]uint16, 0, len(indices))
+	newIndices = append(newIndices, indices[:slip39MetadataWords]...)
+	newIndices = append(newIndices, unpackIndices(data, valueWords, wordBitSize)...)
+	return append(newIndices, rs1024Checksum(slip39Customization(newIndices), newIndices)...)
+}
+
+// slip39Value returns the packed share value words and the number of
+// leading padding bits. The value itself is a whole number of 16-bit units.
+func slip39Value(indices []uint16) (data []byte, paddingLen int) {
+	valueIndices := indices[slip39MetadataWords : len(indices)-slip39ChecksumWords]
+	wordBitSize := SLIP39.BitsPerWord()
+	return packIndices(valueIndices, wordBitSize), len(valueIndices) * wordBitSize % 16
+}
+
+// slip39Customization returns the RS1024 customization string, which
+// depends on the extendable backup flag of the share.
+func slip39Customization(indices []uint16) string {
+	if indices[1]>>4&1 == 1 {
+		return "shamir_extendable"
+	}
+	return "shamir"
+}
+
+// slip39SetIdentifier replaces the identifier, the first 15 bits of the
+// share, with id. The checksum must be recomputed afterwards.
+func slip39SetIdentifier(indices []uint16, id uint16) {
+	indices[0] = id >> 5
+	indices[1] = id&0x1F<<5 | indices[1]&0x1F
+}
+
+func rs1024Polymod(customization string, values []uint16) uint32 {
+	chk := uint32(1)
+	step := func(v uint16) {
+		b := chk >> 20
+		chk = (chk&0xFFFFF)<<10 ^ uint32(v)
+		for i := 0; i < 10; i++ {
+			if (b>>i)&1 != 0 {
+				chk ^= rs1024Generator[i]
+			}
+		}
+	}
+	for i := 0; i < len(customization); i++ {
+		step(uint16(customization[i]))
+	}
+	for _, v := range values {
+		step(v)
+	}
+	return chk
+}
+
+// rs1024Checksum returns the three checksum words for the share data in
+// values.
+func rs1024Checksum(customization string, values []uint16) []uint16 {
+	polymod := rs1024Polymod(customization, append(append([]uint16{}, values...), 0, 0, 0)) ^ 1
+	checksum := make([]uint16, slip39ChecksumWords)
+	for i := range checksum {
+		checksum[i] = uint16(polymod>>(10*(2-i))) & 1023
+	}
+	return checksum
+}
@@ -0,0 +1,153 @@
+package scrambler
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSuggestions is the most words Suggest returns.
+	maxSuggestions = 3
+	// maxTypoCost is the largest typoCost of a suggested word: two
+	// ordinary typos, or up to four slips onto a neighbouring key.
+	maxTypoCost = 4
+)
+
+// standardLists are the wordlists a word may come from by mistake.
+var standardLists = []struct {
+	name string
+	list Wordlist
+}{
+	{"BIP39", BIP39},
+	{"SLIP39", SLIP39},
+}
+
+// qwertyRows is the letter layout used to tell slips onto a neighbouring key
+// from other typos.
+var qwertyRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// keyPositions maps every letter to its row and column on the keyboard.
+var keyPositions = func() map[byte][2]int {
+	positions := map[byte][2]int{}
+	for row, letters := range qwertyRows {
+		for col := 0; col < len(letters); col++ {
+			positions[letters[col]] = [2]int{row, col}
+		}
+	}
+	return positions
+}()
+
+// NormalizeWord trims surrounding whitespace from a typed word and lowers
+// the case of its ASCII letters in place, so it can be looked up in a
+// wordlist. The returned slice aliases word.
+func NormalizeWord(word []byte) []byte {
+	word = bytes.TrimSpace(word)
+	for i, c := range word {
+		if 'A' <= c && c <= 'Z' {
+			word[i] = c + 'a' - 'A'
+		}
+	}
+	return word
+}
+
+// Name returns the name of a standard wordlist, or "" for any other list.
+func (w Wordlist) Name() string {
+	for _, s := range standardLists {
+		if len(s.list) == len(w) && len(w) > 0 && &s.list[0] == &w[0] {
+			return s.name
+		}
+	}
+	return ""
+}
+
+// OtherWordlist returns the name of a standard wordlist that contains word
+// when w does not, which means the word was most likely taken from the
+// wrong list.
+func (w Wordlist) OtherWordlist(word string) (string, bool) {
+	if w.Contains(word) {
+		return "", false
+	}
+	for _, s := range standardLists {
+		if s.list.Contains(word) {
+			return s.name, true
+		}
+	}
+	return "", false
+}
+
+// Suggest returns up to three words of the list that word is most likely a
+// typo of, best match first, leaving out words much less likely than the
+// best. Typos are counted as missing, extra, swapped and wrong letters, with
+// a wrong letter from a neighbouring key counting half.
+func (w Wordlist) Suggest(word string) []string {
+	type match struct {
+		word string
+		cost int
+	}
+	word = strings.ToLower(word)
+	var matches []match
+	for _, candidate := range w {
+		if cost := typoCost(word, candidate); cost <= maxTypoCost {
+			matches = append(matches, match{candidate, cost})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].cost < matches[j].cost })
+	suggestions := []string{}
+	for _, m := range matches {
+		// Words clearly worse than the best match are noise.
+		if len(suggestions) == maxSuggestions || m.cost > matches[0].cost+1 {
+			break
+		}
+		suggestions = append(suggestions, m.word)
+	}
+	return suggestions
+}
+
+// typoCost is the optimal string alignment distance between a and b, with
+// every edit costing 2 except a substitution of neighbouring keys, which
+// costs 1.
+func typoCost(a, b string) int {
+	if d := len(a) - len(b); d > maxTypoCost/2 || -d > maxTypoCost/2 {
+		return maxTypoCost + 1
+	}
+	// rows[i][j] is the cost of turning a[:i] into b[:j].
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = 2 * i
+	}
+	for j := range rows[0] {
+		rows[0][j] = 2 * j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := min(rows[i-1][j]+2, rows[i][j-1]+2, rows[i-1][j-1]+substitutionCost(a[i-1], b[j-1]))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cost = min(cost, rows[i-2][j-2]+2)
+			}
+			rows[i][j] = cost
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+func substitutionCost(a, b byte) int {
+	if a == b {
+		return 0
+	}
+	pa, okA := keyPositions[a]
+	pb, okB := keyPositions[b]
+	if !okA || !okB {
+		return 2
+	}
+	// Each row is shifted half a key to the right of the one above it.
+	row, col := pb[0]-pa[0], pb[1]-pa[1]
+	switch {
+	case row == 0 && (col == -1 || col == 1),
+		row == -1 && (col == 0 || col == 1),
+		row == 1 && (col == -1 || col == 0):
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,69 @@
+package scrambler
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	for _, tt := range []struct {
+		list  Wordlist
+		typed string
+		want  []string
+	}{
+		{SLIP39, "acadmic", []string{"academic"}},  // missing letter
+		{SLIP39, "acsdemic", []string{"academic"}}, // neighbouring key
+		{SLIP39, "caademic", []string{"academic"}}, // swapped letters
+		{SLIP39, "ACADEMIC", []string{"academic"}},
+		{SLIP39, "qqqqqqqq", []string{}},
+		{BIP39, "abandom", []string{"abandon"}},
+	} {
+		if got := tt.list.Suggest(tt.typed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q) = %q, want %q", tt.typed, got, tt.want)
+		}
+	}
+}
+
+func TestTypoCost(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"scramble", "scramble", 0},
+		{"scramble", "scrambel", 2},
+		{"scramble", "scramblx", 2},
+		{"scramble", "acramble", 1},
+		{"scramble", "zcramble", 1},
+		{"scramble", "scrable", 2},
+	} {
+		if got := typoCost(tt.a, tt.b); got != tt.want {
+			t.Errorf("typoCost(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeWord(t *testing.T) {
+	if got := string(NormalizeWord([]byte(" \tAcaDemic \r"))); got != "academic" {
+		t.Errorf("NormalizeWord = %q", got)
+	}
+}
+
+func TestUnknownWordError(t *testing.T) {
+	s := New(WithProfile(testProfile))
+	words := []string{"academic", "acid", "acne", "acquire", "acrobat", "activity", "actress", "adapt", "adequate", "adjust", "admit", "zero"}
+	words[2] = "abandon"
+	_, err := s.Scramble(words, []byte("password"), nil)
+	var unknown *UnknownWordError
+	if !errors.As(err, &unknown) || unknown.Other != "BIP39" || unknown.Position != 3 {
+		t.Errorf("BIP39 word in SLIP39 list: %v", err)
+	}
+	words[2] = "acme"
+	_, err = s.Scramble(words, []byte("password"), nil)
+	if !errors.As(err, &unknown) || unknown.Other != "" || len(unknown.Suggestions) == 0 || unknown.Suggestions[0] != "acne" {
+		t.Errorf("typo: %v", err)
+	}
+	if SLIP39.Name() != "SLIP39" || BIP39.Name() != "BIP39" || (Wordlist{"a"}).Name() != "" {
+		t.Error("wrong wordlist names")
+	}
+}
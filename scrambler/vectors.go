@@ -0,0 +1,112 @@
+package scrambler
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+//go:embed testdata/vectors.json
+var vectorsJSON []byte
+
+// testProfile is a deliberately weak profile that keeps known-answer tests
+// fast. It is not registered, so it can never be chosen for a real backup.
+var testProfile = KDFProfile{
+	ID:            "test",
+	SHA3Rounds:    1000,
+	Argon2Time:    1,
+	Argon2Memory:  64,
+	Argon2Threads: 1,
+	KeyLen:        64,
+}
+
+// Vector is a known-answer test: scrambling Words with Password and Salt in
+// Mode, under the wallet Label if any, must give Scrambled, and unscrambling
+// Scrambled must give Words. When CheckWords is set it must be the check
+// words of Scrambled.
+type Vector struct {
+	Name       string   `json:"name"`
+	Mode       string   `json:"mode"`
+	Profile    string   `json:"profile"`
+	Label      string   `json:"label,omitempty"`
+	Password   string   `json:"password"`
+	Salt       []string `json:"salt"`
+	Words      []string `json:"words"`
+	Scrambled  []string `json:"scrambled"`
+	CheckWords []string `json:"check_words,omitempty"`
+}
+
+// Vectors returns the built-in test vectors.
+func Vectors() ([]Vector, error) {
+	var vectors []Vector
+	if err := json.Unmarshal(vectorsJSON, &vectors); err != nil {
+		return nil, fmt.Errorf("parsing test vectors: %w", err)
+	}
+	return vectors, nil
+}
+
+// Full reports whether the vector uses a real profile, which takes as long
+// as scrambling an actual backup.
+func (v Vector) Full() bool {
+	return v.Profile != testProfile.ID
+}
+
+// Check runs the vector and returns an error describing any mismatch.
+func (v Vector) Check() error {
+	mode, ok := ModeByName(v.Mode)
+	if !ok {
+		return fmt.Errorf("vector %s: unknown mode %q", v.Name, v.Mode)
+	}
+	profile, ok := LookupProfile(v.Profile)
+	if !v.Full() {
+		profile, ok = testProfile, true
+	}
+	if !ok {
+		return fmt.Errorf("vector %s: unknown KDF profile %q", v.Name, v.Profile)
+	}
+
+	s := New(WithMode(mode), WithProfile(profile), WithLabel(v.Label))
+	key, err := s.DeriveKey([]byte(v.Password), v.Salt)
+	if err != nil {
+		return fmt.Errorf("vector %s: %w", v.Name, err)
+	}
+	defer key.Wipe()
+
+	scrambled, err := key.Scramble(v.Words)
+	if err != nil {
+		return fmt.Errorf("vector %s: scramble: %w", v.Name, err)
+	}
+	if !equalWords(scrambled, v.Scrambled) {
+		return fmt.Errorf("vector %s: scrambled to %q, want %q", v.Name, strings.Join(scrambled, " "), strings.Join(v.Scrambled, " "))
+	}
+	unscrambled, err := key.Unscramble(v.Scrambled)
+	if err != nil {
+		return fmt.Errorf("vector %s: unscramble: %w", v.Name, err)
+	}
+	if !equalWords(unscrambled, v.Words) {
+		return fmt.Errorf("vector %s: unscrambled to %q, want %q", v.Name, strings.Join(unscrambled, " "), strings.Join(v.Words, " "))
+	}
+	if len(v.CheckWords) > 0 {
+		check, err := key.CheckWords(v.Scrambled, len(v.CheckWords))
+		if err != nil {
+			return fmt.Errorf("vector %s: check words: %w", v.Name, err)
+		}
+		if !equalWords(check, v.CheckWords) {
+			return fmt.Errorf("vector %s: check words %q, want %q", v.Name, strings.Join(check, " "), strings.Join(v.CheckWords, " "))
+		}
+	}
+	return nil
+}
+
+func equalWords(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
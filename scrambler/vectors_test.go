@@ -0,0 +1,94 @@
+package scrambler
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestVectors(t *testing.T) {
+	vectors, err := Vectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			if v.Full() && os.Getenv("SCRAMBLER_FULL_VECTORS") == "" {
+				t.Skip("full-parameter KDF vector; set SCRAMBLER_FULL_VECTORS=1 to run it")
+			}
+			if err := v.Check(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestVectorCoverage(t *testing.T) {
+	vectors, err := Vectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]map[int]bool{}
+	fullProfiles := map[string]bool{}
+	var emptySalt, multiWordSalt, checked, labelled bool
+	for _, v := range vectors {
+		if counts[v.Mode] == nil {
+			counts[v.Mode] = map[int]bool{}
+		}
+		counts[v.Mode][len(v.Words)] = true
+		emptySalt = emptySalt || len(v.Salt) == 0
+		multiWordSalt = multiWordSalt || len(v.Salt) > 1
+		checked = checked || len(v.CheckWords) > 0
+		labelled = labelled || v.Label != ""
+		if v.Full() {
+			fullProfiles[v.Profile] = true
+		}
+	}
+	for _, mode := range Modes {
+		for _, n := range mode.WordCounts() {
+			if !counts[mode.Name()][n] {
+				t.Errorf("no %s vector with %d words", mode.Name(), n)
+			}
+		}
+	}
+	for _, profile := range Profiles() {
+		if !fullProfiles[profile.ID] {
+			t.Errorf("no full-parameter vector for KDF profile %s", profile.ID)
+		}
+	}
+	if !emptySalt || !multiWordSalt || !checked || !labelled {
+		t.Errorf("missing vectors: empty salt %v, multi-word salt %v, check words %v, label %v",
+			emptySalt, multiWordSalt, checked, labelled)
+	}
+}
+
+func TestScrambleErrors(t *testing.T) {
+	s := New(WithProfile(testProfile))
+	password := []byte("password")
+	words := strings.Fields("academic acid acne acquire acrobat activity actress adapt adequate adjust admit zero")
+
+	var lengthErr *LengthError
+	if _, err := s.Scramble(words[:11], password, nil); !errors.As(err, &lengthErr) {
+		t.Errorf("11 words: got %v, want *LengthError", err)
+	}
+	if _, err := s.Scramble(words, password, make([]string, MaxSaltWords+1)); !errors.As(err, &lengthErr) || !lengthErr.Salt {
+		t.Errorf("too many salt words: got %v, want salt *LengthError", err)
+	}
+
+	var wordErr *UnknownWordError
+	typo := append([]string{}, words...)
+	typo[3] = "acquired"
+	if _, err := s.Scramble(typo, password, nil); !errors.As(err, &wordErr) || wordErr.Position != 4 {
+		t.Errorf("unknown word: got %v, want *UnknownWordError at position 4", err)
+	}
+	if _, err := s.Scramble(words, password, []string{"zero", "abandon"}); !errors.As(err, &wordErr) || !wordErr.Salt {
+		t.Errorf("unknown salt word: got %v, want salt *UnknownWordError", err)
+	}
+
+	bip39 := New(WithMode(ModeBIP39), WithProfile(testProfile))
+	mnemonic := strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")
+	if _, err := bip39.Scramble(mnemonic, password, nil); !errors.Is(err, ErrChecksum) {
+		t.Errorf("BIP39 checksum: got %v, want ErrChecksum", err)
+	}
+}
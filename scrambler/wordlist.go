@@ -0,0 +1,62 @@
+package scrambler
+
+import (
+	"math"
+	"strings"
+)
+
+// Wordlist is an ordered list of mnemonic words. The position of a word in
+// the list is its numeric value.
+type Wordlist []string
+
+// Index returns the position of word in the list, or -1 if it is not present.
+func (w Wordlist) Index(word string) int {
+	for i, candidate := range w {
+		if candidate == word {
+			return i
+		}
+	}
+	return -1
+}
+
+// Contains reports whether word is part of the list.
+func (w Wordlist) Contains(word string) bool {
+	return w.Index(word) >= 0
+}
+
+// BitsPerWord returns the number of bits encoded by a single word.
+func (w Wordlist) BitsPerWord() int {
+	return int(math.Log2(float64(len(w))))
+}
+
+// MinPrefix is the shortest prefix Resolve accepts for a word. The first
+// four letters identify every word of the BIP39 and SLIP39 lists.
+const MinPrefix = 4
+
+// Complete returns the words of the list that start with prefix, in list
+// order.
+func (w Wordlist) Complete(prefix string) []string {
+	var matches []string
+	for _, candidate := range w {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// Resolve returns the list's own copy of the word typed as s: the word
+// itself, or the only word starting with s when s is at least MinPrefix
+// bytes long.
+func (w Wordlist) Resolve(s string) (string, bool) {
+	if i := w.Index(s); i >= 0 {
+		return w[i], true
+	}
+	if len(s) < MinPrefix {
+		return "", false
+	}
+	if matches := w.Complete(s); len(matches) == 1 {
+		return matches[0], true
+	}
+	return "", false
+}
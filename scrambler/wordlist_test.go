@@ -0,0 +1,43 @@
+package scrambler
+
+import "testing"
+
+func TestPrefixesAreUnique(t *testing.T) {
+	for name, list := range map[string]Wordlist{"bip39": BIP39, "slip39": SLIP39} {
+		seen := map[string]string{}
+		for _, word := range list {
+			prefix := word[:min(len(word), MinPrefix)]
+			if other, ok := seen[prefix]; ok {
+				t.Errorf("%s: %q and %q share the prefix %q", name, other, word, prefix)
+			}
+			seen[prefix] = word
+			if got, ok := list.Resolve(prefix); !ok || got != word {
+				t.Errorf("%s: Resolve(%q) = %q, %v, want %q", name, prefix, got, ok, word)
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	for _, tt := range []struct {
+		list  Wordlist
+		typed string
+		want  string
+	}{
+		{SLIP39, "academic", "academic"},
+		{SLIP39, "acad", "academic"},
+		{SLIP39, "acade", "academic"},
+		{SLIP39, "acadx", ""},
+		{SLIP39, "aca", ""},
+		{SLIP39, "", ""},
+		{BIP39, "act", "act"},
+		{BIP39, "acti", "action"},
+		{BIP39, "actix", ""},
+		{BIP39, "zoo", "zoo"},
+	} {
+		got, ok := tt.list.Resolve(tt.typed)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.typed, got, ok, tt.want)
+		}
+	}
+}
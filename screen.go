@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// displayTimeout is how long secrets stay on screen without a key press.
+const displayTimeout = 10 * time.Minute
+
+// stdoutTerminal reports whether standard output is a terminal. Screen
+// control sequences are only written to a terminal.
+var stdoutTerminal = term.IsTerminal(int(os.Stdout.Fd()))
+
+// altScreen remembers whether the session switched to the alternate screen,
+// so a signal handler can switch back.
+var altScreen struct {
+	sync.Mutex
+	active bool
+}
+
+// enterAltScreen switches the terminal to the alternate screen, which has
+// no scrollback, so nothing shown during the session stays on the terminal
+// once it ends.
+func enterAltScreen() {
+	if !stdoutTerminal {
+		return
+	}
+	altScreen.Lock()
+	defer altScreen.Unlock()
+	fmt.Print("\033[?1049h\033[H")
+	altScreen.active = true
+}
+
+// leaveAltScreen clears the alternate screen, switches back to the main
+// screen and purges its scrollback. It does nothing unless enterAltScreen
+// switched screens.
+func leaveAltScreen() {
+	altScreen.Lock()
+	defer altScreen.Unlock()
+	if !altScreen.active {
+		return
+	}
+	fmt.Print("\033[H\033[2J\033[3J\033[?1049l\033[3J")
+	altScreen.active = false
+}
+
+// clearScreen clears the terminal and its scrollback and moves the cursor to
+// the top.
+func clearScreen() {
+	if stdoutTerminal {
+		fmt.Print("\033[H\033[2J\033[3J")
+	}
+}
+
+// displaySecrets shows secrets with show on a cleared screen, followed by
+// note, until a key is pressed or displayTimeout passes, and then clears the
+// screen again.
+func displaySecrets(p *prompter, show func(), note string) error {
+	clearScreen()
+	show()
+	printStyled(note)
+	minutes := int(displayTimeout / time.Minute)
+	printStyled(fmt.Sprintf("\n{bold}{cyan}Press any key when you are done. The screen is cleared then, or after %d minutes.\n", minutes))
+	hidden := make(chan struct{})
+	timer := time.AfterFunc(displayTimeout, func() {
+		defer close(hidden)
+		clearScreen()
+		printStyled(fmt.Sprintf("{yellow}The words were hidden after %d minutes. Press any key to continue.\n", minutes))
+	})
+	err := p.readKey()
+	if !timer.Stop() {
+		<-hidden
+	}
+	clearScreen()
+	return err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"walletscrambler/scrambler"
+)
+
+// runSelfTest checks the built-in test vectors so a binary can be verified on
+// the air-gapped machine before it is trusted with a real backup.
+func runSelfTest(args []string) int {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	quick := fs.Bool("quick", false, "skip the vectors that use full KDF parameters")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "selftest: unexpected argument %q\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	vectors, err := scrambler.Vectors()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "selftest: %v\n", err)
+		return exitError
+	}
+	failed, skipped, noMemory := 0, 0, 0
+	for _, v := range vectors {
+		if v.Full() {
+			if *quick {
+				fmt.Printf("skip %s\n", v.Name)
+				skipped++
+				continue
+			}
+			if profile, ok := scrambler.LookupProfile(v.Profile); ok {
+				if err := checkMemory(profile); err != nil {
+					fmt.Printf("skip %s: %v\n", v.Name, err)
+					skipped++
+					noMemory++
+					continue
+				}
+			}
+			fmt.Printf("running %s with the full %s KDF profile, this takes a while...\n", v.Name, v.Profile)
+		}
+		start := time.Now()
+		if err := v.Check(); err != nil {
+			fmt.Printf("FAIL %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("ok   %s (%s)\n", v.Name, time.Since(start).Round(time.Millisecond))
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d of %d vectors FAILED - do not use this binary\n", failed, len(vectors))
+		return exitError
+	}
+	if noMemory > 0 {
+		fmt.Printf("\npartial: %d of %d vectors passed, %d skipped for lack of memory - their KDF profiles are not verified\n",
+			len(vectors)-skipped, len(vectors), noMemory)
+		return exitError
+	}
+	if skipped > 0 {
+		fmt.Printf("\npartial: %d of %d vectors passed, %d full-parameter vectors skipped (-quick)\n", len(vectors)-skipped, len(vectors), skipped)
+		return exitOK
+	}
+	fmt.Println("\nall vectors passed")
+	return exitOK
+}
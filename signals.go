@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"walletscrambler/internal/secmem"
+)
+
+// wipeOnSignal wipes every secret held in secure memory, restores the
+// terminal, clearing anything shown on it, and exits when the process is
+// interrupted or terminated.
+func wipeOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		secmem.WipeAll()
+		restoreTerminal()
+		leaveAltScreen()
+		fmt.Fprintln(os.Stderr, "\nInterrupted, secrets wiped.")
+		if sig == syscall.SIGTERM {
+			os.Exit(exitTerminated)
+		}
+		os.Exit(exitInterrupted)
+	}()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"walletscrambler/internal/secmem"
+)
+
+// errInterrupted is returned when the user presses Ctrl-C at a hidden prompt.
+// The terminal is in raw mode at that point, so no SIGINT is delivered.
+var errInterrupted = errors.New("interrupted")
+
+// passwordMask is echoed for every character typed at a hidden prompt.
+const passwordMask = '*'
+
+// rawTerminal remembers the terminal state to restore while a hidden prompt
+// has the terminal in raw mode, so a signal handler can put it back.
+var rawTerminal struct {
+	sync.Mutex
+	fd    int
+	state *term.State
+}
+
+func makeRaw(fd int) error {
+	rawTerminal.Lock()
+	defer rawTerminal.Unlock()
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	rawTerminal.fd, rawTerminal.state = fd, state
+	return nil
+}
+
+// restoreTerminal leaves raw mode if a hidden prompt entered it.
+func restoreTerminal() {
+	rawTerminal.Lock()
+	defer rawTerminal.Unlock()
+	if rawTerminal.state != nil {
+		term.Restore(rawTerminal.fd, rawTerminal.state)
+		rawTerminal.state = nil
+	}
+}
+
+// terminalFd returns the file descriptor of in if it is a terminal, or -1.
+func terminalFd(in io.Reader) int {
+	f, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return -1
+	}
+	return int(f.Fd())
+}
+
+// readHidden reads one line from the terminal without echoing it, printing
+// passwordMask for every character instead. When the input is not a
+// terminal it falls back to readLine.
+func (p *prompter) readHidden() (*secmem.Buffer, error) {
+	if p.fd < 0 {
+		return p.readLine()
+	}
+	return p.readRaw(passwordMask, nil)
+}
+
+// readKey waits for a single key press without echoing it, so nothing typed
+// lands on a screen that shows secrets. Ctrl-C returns errInterrupted and
+// Ctrl-D errInputClosed. When the input is not a terminal it reads a line
+// instead.
+func (p *prompter) readKey() error {
+	if p.fd < 0 {
+		_, err := p.line()
+		return err
+	}
+	if err := makeRaw(p.fd); err != nil {
+		return err
+	}
+	defer restoreTerminal()
+	// Keys such as the arrows send several bytes at once; read them all so
+	// none is left for the next prompt.
+	var b [16]byte
+	for {
+		n, err := p.in.Read(b[:])
+		if n > 0 {
+			switch b[0] {
+			case 3: // Ctrl-C
+				return errInterrupted
+			case 4: // Ctrl-D
+				return errInputClosed
+			}
+			return nil
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errInputClosed
+			}
+			return err
+		}
+	}
+}
+
+// readRaw reads one line from the terminal in raw mode, echoing mask for
+// every character typed, or the character itself when mask is zero. When
+// complete is not nil, Tab appends what it returns for the line so far and
+// rings the bell when that is nothing.
+func (p *prompter) readRaw(mask byte, complete func(line []byte) string) (*secmem.Buffer, error) {
+	if err := makeRaw(p.fd); err != nil {
+		return nil, err
+	}
+	defer restoreTerminal()
+
+	line := secmem.New(maxLineLen)
+	var b [1]byte
+	for {
+		n, err := p.in.Read(b[:])
+		if err != nil && n == 0 {
+			if errors.Is(err, io.EOF) {
+				err = errInputClosed
+			}
+			line.Destroy()
+			return nil, err
+		}
+		switch b[0] {
+		case '\r', '\n':
+			p.out.Write([]byte("\r\n"))
+			return line, nil
+		case 3: // Ctrl-C
+			line.Destroy()
+			p.out.Write([]byte("\r\n"))
+			return nil, errInterrupted
+		case 4: // Ctrl-D
+			if line.Len() == 0 {
+				line.Destroy()
+				p.out.Write([]byte("\r\n"))
+				return nil, errInputClosed
+			}
+		case '\t':
+			if complete == nil {
+				continue
+			}
+			suffix := complete(line.Bytes())
+			if suffix == "" {
+				p.out.Write([]byte{'\a'})
+				continue
+			}
+			if _, err := line.Write([]byte(suffix)); err != nil {
+				line.Destroy()
+				return nil, errLineTooLong
+			}
+			p.echo(mask, []byte(suffix))
+		case 8, 127: // Backspace
+			if line.Len() == 0 {
+				continue
+			}
+			_, size := utf8.DecodeLastRune(line.Bytes())
+			line.Truncate(line.Len() - size)
+			p.out.Write([]byte("\b \b"))
+		case 21: // Ctrl-U
+			for i := utf8.RuneCount(line.Bytes()); i > 0; i-- {
+				p.out.Write([]byte("\b \b"))
+			}
+			line.Wipe()
+		default:
+			if b[0] < ' ' {
+				continue
+			}
+			if line.AppendByte(b[0]) != nil {
+				line.Destroy()
+				return nil, errLineTooLong
+			}
+			p.echo(mask, b[:])
+		}
+	}
+}
+
+// echo shows typed bytes on the terminal: as they are when mask is zero,
+// otherwise as one mask per character.
+func (p *prompter) echo(mask byte, typed []byte) {
+	if mask == 0 {
+		p.out.Write(typed)
+		return
+	}
+	for _, c := range typed {
+		if utf8.RuneStart(c) {
+			p.out.Write([]byte{mask})
+		}
+	}
+}